@@ -2,14 +2,19 @@ package app
 
 import (
 	"bytes"
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
+	"maps"
 	"net"
+	"net/http"
 	"os"
 	"os/signal"
 	"runtime"
 	"syscall"
+	"time"
 
 	"github.com/code19m/sentinel/config"
 	"github.com/code19m/sentinel/pb"
@@ -29,8 +34,13 @@ type app struct {
 	logger *slog.Logger
 	cfg    config.Config
 
-	pgConn *pgxpool.Pool
-	server *grpc.Server
+	pgConn     *pgxpool.Pool
+	store      store.Store
+	server     *grpc.Server
+	httpServer *http.Server
+	dispatcher usecase.Dispatcher
+	detector   usecase.SpikeDetector
+	digests    *digestScheduler
 }
 
 func New(
@@ -59,13 +69,32 @@ func New(
 		os.Exit(1)
 	}
 
-	notifier, err := defineNotifier(cfg)
+	notifier, targets, err := defineNotifier(cfg)
 	if err != nil {
 		logger.ErrorContext(ctx, "Failed to create notifier", slog.Any("error", err))
 		os.Exit(1)
 	}
 
-	usecase := usecase.New(logger, pgStore, notifier, cfg.AlertCooldownMinutes)
+	normalizeRules, err := usecase.ParseNormalizeRules(cfg.NormalizeRules)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to parse normalize rules", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	conditions, err := defineConditions(cfg)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to define alert conditions", slog.Any("error", err))
+		os.Exit(1)
+	}
+
+	usecase := usecase.New(defineAlertDelivery(cfg), logger, pgStore, notifier, usecase.NewNormalizer(normalizeRules...),
+		usecase.NewConditions(cfg.Environment, logger, pgStore, conditions...), defineSpikeDetection(cfg))
+
+	digests, err := defineDigests(cfg, targets)
+	if err != nil {
+		logger.ErrorContext(ctx, "Failed to define digests", slog.Any("error", err))
+		os.Exit(1)
+	}
 
 	sentinelServer := server.NewSentinelServer(cfg, logger, usecase)
 
@@ -84,17 +113,33 @@ func New(
 	grpcServer := grpc.NewServer(
 		grpc.ChainUnaryInterceptor(
 			recovery.UnaryServerInterceptor(
-				recovery.WithRecoveryHandler(grpcPanicRecoveryHandler))))
+				recovery.WithRecoveryHandler(grpcPanicRecoveryHandler)),
+			server.UnaryAuthInterceptor(cfg)),
+		grpc.ChainStreamInterceptor(
+			recovery.StreamServerInterceptor(
+				recovery.WithRecoveryHandler(grpcPanicRecoveryHandler)),
+			server.StreamAuthInterceptor(cfg)))
 
 	// Register service
 	pb.RegisterSentinelServiceServer(grpcServer, sentinelServer)
 	reflection.Register(grpcServer)
 
+	httpServer := &http.Server{
+		Addr:              fmt.Sprintf("%s:%s", cfg.HttpHost, cfg.HttpPort),
+		Handler:           server.NewHTTPHandler(cfg, logger, usecase),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+
 	return &app{
-		logger: logger,
-		cfg:    cfg,
-		pgConn: pgConn,
-		server: grpcServer,
+		logger:     logger,
+		cfg:        cfg,
+		pgConn:     pgConn,
+		store:      pgStore,
+		server:     grpcServer,
+		httpServer: httpServer,
+		dispatcher: usecase,
+		detector:   usecase,
+		digests:    newDigestScheduler(logger, usecase, digests),
 	}
 }
 
@@ -107,35 +152,292 @@ func (a *app) Start() {
 		os.Exit(1)
 	}
 
-	a.logger.InfoContext(ctx, "Server started", slog.String("address", listener.Addr().String()))
+	dispatchCtx, stopDispatcher := context.WithCancel(ctx)
+	dispatcherDone := make(chan struct{})
+	go func() {
+		defer close(dispatcherDone)
+		a.dispatcher.DispatchAlerts(dispatchCtx)
+	}()
 
-	err = a.server.Serve(listener)
-	if err != nil {
-		a.logger.ErrorContext(ctx, "Failed to serve", slog.Any("error", err))
-		os.Exit(1)
-	}
+	detectorDone := make(chan struct{})
+	go func() {
+		defer close(detectorDone)
+		a.detector.DetectSpikes(dispatchCtx)
+	}()
+
+	digestsDone := make(chan struct{})
+	go func() {
+		defer close(digestsDone)
+		a.digests.Run(dispatchCtx)
+	}()
+
+	// Until done, queries are slower and older errors are missing from search and stats
+	migrateDone := make(chan struct{})
+	go func() {
+		defer close(migrateDone)
+		err := a.store.Migrate(dispatchCtx)
+		if err != nil && dispatchCtx.Err() == nil {
+			a.logger.ErrorContext(ctx, "Failed to migrate", slog.Any("error", err))
+		}
+	}()
+
+	go func() {
+		a.logger.InfoContext(ctx, "Server started", slog.String("address", listener.Addr().String()))
+
+		err := a.server.Serve(listener)
+		if err != nil {
+			a.logger.ErrorContext(ctx, "Failed to serve", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}()
+
+	go func() {
+		a.logger.InfoContext(ctx, "HTTP server started", slog.String("address", a.httpServer.Addr))
+
+		err := a.httpServer.ListenAndServe()
+		if err != nil && !errors.Is(err, http.ErrServerClosed) {
+			a.logger.ErrorContext(ctx, "Failed to serve HTTP", slog.Any("error", err))
+			os.Exit(1)
+		}
+	}()
 
 	// Graceful Shutdown
 	quit := make(chan os.Signal, 1)
 	signal.Notify(quit, syscall.SIGTERM, syscall.SIGINT)
 
 	<-quit
+	shutdownCtx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+
+	err = a.httpServer.Shutdown(shutdownCtx)
+	if err != nil {
+		a.logger.ErrorContext(ctx, "Failed to shutdown HTTP server", slog.Any("error", err))
+	}
 	a.server.GracefulStop()
+
+	stopDispatcher()
+	<-dispatcherDone
+	<-detectorDone
+	<-digestsDone
+	<-migrateDone
 	a.pgConn.Close()
 
 	a.logger.InfoContext(ctx, "Server stopped")
 }
 
-func defineNotifier(cfg config.Config) (notifier.Notifier, error) {
-	switch cfg.AlertProvider {
+// defineNotifier returns the alert router and the targets by name.
+func defineNotifier(cfg config.Config) (notifier.Notifier, map[string]notifier.Target, error) {
+	targets := make(map[string]notifier.Target)
+	providers := make(map[string]string) // Providers by target name
+
+	defaults := make([]notifier.Target, 0, len(cfg.AlertProviders))
+	for _, tc := range cfg.DefaultTargets() {
+		t, err := defineTarget(cfg, tc)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets[t.Name] = t
+		providers[t.Name] = tc.Provider
+		defaults = append(defaults, t)
+	}
+
+	for _, tc := range cfg.Alerting.Targets {
+		t, err := defineTarget(cfg, tc)
+		if err != nil {
+			return nil, nil, err
+		}
+		targets[t.Name] = t
+		providers[t.Name] = tc.Provider
+	}
+
+	routes := make([]notifier.Route, 0, len(cfg.Alerting.Routes))
+	for _, rc := range cfg.Alerting.Routes {
+		r, err := defineRoute(rc, targets, providers)
+		if err != nil {
+			return nil, nil, err
+		}
+		routes = append(routes, r)
+	}
+
+	return notifier.NewRouter(cfg.Environment, routes, defaults), targets, nil
+}
+
+func defineTarget(cfg config.Config, tc config.TargetConfig) (notifier.Target, error) {
+	var (
+		n   notifier.Notifier
+		err error
+	)
+
+	switch tc.Provider {
 
 	case config.AlertProviderTelegram:
-		return notifier.NewTelegramNotifier(cfg.TelegramBotToken, cfg.TelegramsChatIDs, cfg.Environment)
+		n, err = notifier.NewTelegramNotifier(cfg.TelegramBotToken, tc.TelegramChatIDs, cfg.Environment)
 
 	case config.AlertProviderDiscord:
-		return notifier.NewDiscordNotifier(cfg.DiscordBotToken, cfg.DiscordChannelIDs, cfg.Environment)
+		n, err = notifier.NewDiscordNotifier(cfg.DiscordBotToken, tc.DiscordChannelIDs, cfg.Environment)
+
+	case config.AlertProviderSlack:
+		n, err = notifier.NewSlackNotifier(cfg.SlackBotToken, tc.SlackChannelIDs, tc.SlackWebhookURL, cfg.Environment)
+
+	case config.AlertProviderEmail:
+		n, err = notifier.NewEmailNotifier(notifier.SMTPConfig{
+			Host:     cfg.SMTPHost,
+			Port:     cfg.SMTPPort,
+			Username: cfg.SMTPUsername,
+			Password: cfg.SMTPPassword,
+			From:     cfg.SMTPFrom,
+			Security: cfg.SMTPSecurity,
+		}, tc.EmailTo, cfg.Environment)
+
+	case config.AlertProviderWebhook:
+		headers := make(map[string]string)
+		maps.Copy(headers, cfg.WebhookHeaders)
+		maps.Copy(headers, tc.WebhookHeaders)
+
+		n, err = notifier.NewWebhookNotifier(notifier.WebhookConfig{
+			URLs:    tc.WebhookURLs,
+			Secret:  cmp.Or(tc.WebhookSecret, cfg.WebhookSecret),
+			Headers: headers,
+			Timeout: cmp.Or(tc.WebhookTimeout, cfg.WebhookTimeout),
+		}, cfg.Environment)
+
+	case config.AlertProviderTeams:
+		n, err = notifier.NewTeamsNotifier(tc.TeamsWebhookURLs, cfg.Environment)
+
+	case config.AlertProviderMattermost:
+		n, err = notifier.NewMattermostNotifier(cfg.MattermostURL, cfg.MattermostBotToken,
+			tc.MattermostChannelIDs, tc.MattermostWebhookURL, cfg.Environment)
 
 	default:
-		return nil, fmt.Errorf("defineNotifier: invalid alert provider: %s", cfg.AlertProvider)
+		err = fmt.Errorf("invalid alert provider: %s", tc.Provider)
+	}
+
+	if err != nil {
+		return notifier.Target{}, fmt.Errorf("defineTarget: %s: %w", tc.Name, err)
+	}
+
+	if tpl, ok := cfg.Alerting.Templates[tc.Provider]; ok {
+		n, err = applyTemplate(n, tpl)
+		if err != nil {
+			return notifier.Target{}, fmt.Errorf("defineTarget: %s: %w", tc.Name, err)
+		}
+	}
+
+	return notifier.Target{Name: tc.Name, Notifier: n}, nil
+}
+
+func defineRoute(rc config.RouteConfig, targets map[string]notifier.Target, providers map[string]string) (notifier.Route, error) {
+	r := notifier.Route{
+		Name:     rc.Name,
+		Continue: rc.Continue,
+	}
+
+	var err error
+	r.Match, err = defineMatch(rc.Match)
+	if err != nil {
+		return r, fmt.Errorf("defineRoute: %s: %w", rc.Name, err)
+	}
+
+	for _, name := range rc.Targets {
+		t := targets[name]
+		if tpl, ok := rc.Templates[providers[name]]; ok {
+			t.Notifier, err = applyTemplate(t.Notifier, tpl)
+			if err != nil {
+				return r, fmt.Errorf("defineRoute: %s: target %s: %w", rc.Name, name, err)
+			}
+		}
+		r.Targets = append(r.Targets, t)
+	}
+
+	return r, nil
+}
+
+func defineConditions(cfg config.Config) ([]usecase.AlertCondition, error) {
+	conditions := make([]usecase.AlertCondition, 0, len(cfg.Alerting.Conditions))
+	for _, cc := range cfg.Alerting.Conditions {
+		match, err := defineMatch(cc.Match)
+		if err != nil {
+			return nil, fmt.Errorf("defineConditions: %s: %w", cc.Name, err)
+		}
+
+		conditions = append(conditions, usecase.AlertCondition{
+			Name:       cc.Name,
+			Match:      match,
+			Type:       usecase.ConditionType(cc.Type),
+			Window:     cc.Window,
+			Threshold:  cc.Threshold,
+			UserDetail: cmp.Or(cc.UserDetail, "user_id"),
+			MinCount:   cc.MinCount,
+		})
+	}
+	return conditions, nil
+}
+
+func defineAlertDelivery(cfg config.Config) usecase.AlertDelivery {
+	return usecase.AlertDelivery{
+		Cooldown:        time.Minute * time.Duration(cfg.AlertCooldownMinutes),
+		PollInterval:    cfg.AlertPollInterval,
+		MaxAttempts:     cfg.AlertMaxAttempts,
+		RetryBaseDelay:  cfg.AlertRetryBaseDelay,
+		RetryMaxDelay:   cfg.AlertRetryMaxDelay,
+		OutboxRetention: cfg.AlertOutboxRetention,
+		Workers:         cfg.AlertWorkers,
+		QueueSize:       cfg.AlertQueueSize,
+		QueueFullPolicy: cfg.AlertQueueFullPolicy,
+	}
+}
+
+func defineSpikeDetection(cfg config.Config) usecase.SpikeDetection {
+	sc := cfg.Alerting.Spikes
+	return usecase.SpikeDetection{
+		Enabled:    sc.Enabled,
+		Window:     cmp.Or(sc.Window, 5*time.Minute),
+		Deviations: cmp.Or(sc.Deviations, 4),
+		MinCount:   cmp.Or(sc.MinCount, 10),
+		Days:       cmp.Or(sc.Days, 7),
+		Cooldown:   cmp.Or(sc.Cooldown, time.Hour),
+	}
+}
+
+func defineMatch(mc config.MatchConfig) (notifier.Match, error) {
+	m := notifier.Match{Details: make(map[string]notifier.Matcher, len(mc.Details))}
+
+	var err error
+	for _, p := range []struct {
+		matcher *notifier.Matcher
+		pattern string
+	}{
+		{&m.Service, mc.Service},
+		{&m.Operation, mc.Operation},
+		{&m.Code, mc.Code},
+		{&m.Environment, mc.Environment},
+	} {
+		*p.matcher, err = notifier.CompilePattern(p.pattern)
+		if err != nil {
+			return m, fmt.Errorf("defineMatch: %w", err)
+		}
+	}
+
+	for key, pattern := range mc.Details {
+		m.Details[key], err = notifier.CompilePattern(pattern)
+		if err != nil {
+			return m, fmt.Errorf("defineMatch: %w", err)
+		}
+	}
+
+	return m, nil
+}
+
+// applyTemplate returns a copy of the notifier rendering messages with the template.
+func applyTemplate(n notifier.Notifier, tc config.TemplateConfig) (notifier.Notifier, error) {
+	tn, ok := n.(notifier.Templatable)
+	if !ok {
+		return nil, fmt.Errorf("applyTemplate: notifier does not support templates")
+	}
+
+	n, err := tn.WithTemplate(tc.Title, tc.Body)
+	if err != nil {
+		return nil, fmt.Errorf("applyTemplate: %w", err)
 	}
+	return n, nil
 }
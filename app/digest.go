@@ -0,0 +1,152 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/code19m/sentinel/config"
+	"github.com/code19m/sentinel/repository/notifier"
+	"github.com/code19m/sentinel/usecase"
+	"github.com/robfig/cron/v3"
+)
+
+// digestJob sends a digest to a target on the schedule of the digest,
+// evaluated in the timezone of the target.
+type digestJob struct {
+	name     string
+	schedule cron.Schedule
+	period   time.Duration // Zero for the time since the previous run
+	limit    int
+	location *time.Location
+	target   notifier.DigestTarget
+}
+
+type digestScheduler struct {
+	logger *slog.Logger
+	sender usecase.DigestSender
+	jobs   []digestJob
+}
+
+func newDigestScheduler(logger *slog.Logger, sender usecase.DigestSender, jobs []digestJob) *digestScheduler {
+	return &digestScheduler{
+		logger: logger,
+		sender: sender,
+		jobs:   jobs,
+	}
+}
+
+// Run sends the digests on their schedules until ctx is canceled.
+func (s *digestScheduler) Run(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for _, job := range s.jobs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			s.run(ctx, job)
+		}()
+	}
+}
+
+func (s *digestScheduler) run(ctx context.Context, job digestJob) {
+	for {
+		next := job.schedule.Next(time.Now().In(job.location))
+
+		timer := time.NewTimer(time.Until(next))
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return
+		case <-timer.C:
+		}
+
+		prev, err := previousRun(job.schedule, next)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to send digest", slog.String("digest", job.name),
+				slog.String("target", job.target.Name), slog.Any("error", err))
+			continue
+		}
+
+		from := next.Add(-job.period)
+		if job.period == 0 {
+			from = prev
+		}
+
+		err = s.sender.SendDigest(ctx, job.name, job.target, from, next, next.Sub(prev), job.limit)
+		if err != nil {
+			s.logger.ErrorContext(ctx, "Failed to send digest", slog.String("digest", job.name),
+				slog.String("target", job.target.Name), slog.Any("error", err))
+		}
+	}
+}
+
+// previousRun returns the last run of the schedule before t. It fails if there
+// was none in the past two years.
+func previousRun(schedule cron.Schedule, t time.Time) (time.Time, error) {
+	// Look back further and further, so that frequent schedules are not stepped
+	// through for long, up to past a leap year for @yearly
+	for back := time.Hour; back < 2*366*24*time.Hour; back *= 2 {
+		var prev time.Time
+		for run := schedule.Next(t.Add(-back)); run.Before(t); run = schedule.Next(run) {
+			prev = run
+		}
+		if !prev.IsZero() {
+			return prev, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("previousRun: no run in the two years before %s", t.Format(time.RFC3339))
+}
+
+func defineDigests(cfg config.Config, targets map[string]notifier.Target) ([]digestJob, error) {
+	timezones := make(map[string]string) // Timezones by target name
+	for _, tc := range cfg.Alerting.Targets {
+		timezones[tc.Name] = tc.Timezone
+	}
+
+	var jobs []digestJob
+	for _, dc := range cfg.Alerting.Digests {
+		schedule, err := cron.ParseStandard(dc.Schedule)
+		if err != nil {
+			return nil, fmt.Errorf("defineDigests: %s: %w", dc.Name, err)
+		}
+
+		// Schedules running less than every two years, like on leap days, are
+		// not supported
+		next := schedule.Next(time.Now())
+		if next.IsZero() {
+			return nil, fmt.Errorf("defineDigests: %s: schedule never runs", dc.Name)
+		}
+		_, err = previousRun(schedule, next)
+		if err != nil {
+			return nil, fmt.Errorf("defineDigests: %s: %w", dc.Name, err)
+		}
+
+		for _, name := range dc.Targets {
+			location, err := time.LoadLocation(timezones[name])
+			if err != nil {
+				return nil, fmt.Errorf("defineDigests: %s: target %s: %w", dc.Name, name, err)
+			}
+
+			n, ok := targets[name].Notifier.(notifier.DigestNotifier)
+			if !ok {
+				return nil, fmt.Errorf("defineDigests: %s: target %s does not support digests", dc.Name, name)
+			}
+
+			jobs = append(jobs, digestJob{
+				name:     dc.Name,
+				schedule: schedule,
+				period:   dc.Period,
+				limit:    cmp.Or(dc.Limit, 5),
+				location: location,
+				target:   notifier.DigestTarget{Name: name, Notifier: n},
+			})
+		}
+	}
+
+	return jobs, nil
+}
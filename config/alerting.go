@@ -0,0 +1,381 @@
+package config
+
+import (
+	"bytes"
+	"fmt"
+	"os"
+	"slices"
+	"time"
+	_ "time/tzdata" // Timezones of targets are loaded even without the system database
+
+	"github.com/robfig/cron/v3"
+	"gopkg.in/yaml.v3"
+)
+
+// AlertingConfig is read from the YAML file at ALERTING_CONFIG.
+//
+// Example:
+//
+//	targets:
+//	  - name: billing-telegram
+//	    provider: telegram
+//	    telegram_chat_ids: [-1001234567890]
+//	    timezone: Asia/Tashkent
+//	routes:
+//	  - name: billing
+//	    match:
+//	      service: "billing-*"
+//	      code: "re:^(INTERNAL|UNAVAILABLE)$"
+//	    targets: [billing-telegram]
+//	    templates:
+//	      telegram:
+//	        title: "<b>{{ .Error.Code }} in {{ .Error.Service }}</b>"
+//	conditions:
+//	  - name: payments-flaky
+//	    match:
+//	      service: payments
+//	    type: count
+//	    window: 5m
+//	    threshold: 50
+//	spikes:
+//	  enabled: true
+//	  deviations: 5
+//	digests:
+//	  - name: weekly
+//	    schedule: "0 9 * * MON"
+//	    targets: [billing-telegram]
+//	templates:
+//	  slack:
+//	    body: "*{{ escape .Error.Message | truncate 200 }}* (first seen {{ humanize .Issue.FirstSeen }})"
+type AlertingConfig struct {
+	Targets   []TargetConfig            `yaml:"targets"`
+	Routes    []RouteConfig             `yaml:"routes"`
+	Templates map[string]TemplateConfig `yaml:"templates"` // Message templates by provider
+
+	Conditions []ConditionConfig `yaml:"conditions"`
+	Spikes     SpikeConfig       `yaml:"spikes"`
+	Digests    []DigestConfig    `yaml:"digests"`
+}
+
+// DigestConfig sends reports of the errors of the past period to its targets:
+// the top new issues, the most frequent issues, the services with the biggest
+// increase of errors and the unresolved issues. The schedule is a cron
+// expression ("0 9 * * *", "@weekly", ...) evaluated in the timezone of each
+// target, and the period is the time since the previous run by default.
+// Targets are the names of alerting targets or of the default targets of
+// ALERT_PROVIDER; the latter have no timezone setting and are scheduled in
+// UTC, so define a target here to schedule its digests in another timezone.
+type DigestConfig struct {
+	Name     string        `yaml:"name"`
+	Schedule string        `yaml:"schedule"`
+	Period   time.Duration `yaml:"period"`
+	Targets  []string      `yaml:"targets"`
+	Limit    int           `yaml:"limit"` // Entries per section, 5 by default
+}
+
+// ConditionConfig holds back the alerts of matching errors until, for errors of
+// the same service, operation and code in the sliding window:
+//   - count: there are at least threshold errors
+//   - rate: there are at least threshold errors per minute on average
+//   - distinct_users: at least threshold distinct values of the user_detail
+//     detail ("user_id" by default) are seen
+//   - percent_increase: there are at least threshold percent more errors than
+//     in the window before, and at least min_count errors
+//
+// Errors matching no condition are alerted right away. Regressions of resolved
+// issues are alerted regardless of conditions. Windows are counted in the
+// database, shared by all replicas, per minute: window must be a multiple of a minute.
+type ConditionConfig struct {
+	Name       string        `yaml:"name"`
+	Match      MatchConfig   `yaml:"match"`
+	Type       string        `yaml:"type"`
+	Window     time.Duration `yaml:"window"`
+	Threshold  float64       `yaml:"threshold"`
+	UserDetail string        `yaml:"user_detail"`
+	MinCount   int64         `yaml:"min_count"`
+}
+
+// SpikeConfig enables the detection of error volume spikes. Every minute, the
+// errors of each service and operation in the last window are compared to
+// their baseline: the median and median absolute deviation of the window
+// counts around the same time of day on the previous days. A spike is
+// alerted when the count exceeds the median by at least deviations scaled
+// deviations and is at least min_count, at most once per cooldown per
+// operation. Lower deviations make the detection more sensitive.
+type SpikeConfig struct {
+	Enabled    bool          `yaml:"enabled"`
+	Window     time.Duration `yaml:"window"`     // 5m by default, a multiple of a minute
+	Deviations float64       `yaml:"deviations"` // 4 by default
+	MinCount   int64         `yaml:"min_count"`  // 10 by default
+	Days       int           `yaml:"days"`       // Days of history learned from, 7 by default
+	Cooldown   time.Duration `yaml:"cooldown"`   // 1h by default
+}
+
+// TemplateConfig holds Go templates of the title and body of alert messages,
+// written in the markup of the provider. They are executed with the
+// environment, the error and its issue, see notifier.TemplateData, and may use
+// the truncate, escape, humanize and detail helpers. An empty template keeps
+// the default rendering of its part.
+type TemplateConfig struct {
+	Title string `yaml:"title"`
+	Body  string `yaml:"body"`
+}
+
+// TargetConfig is a named destination of alerts. Provider credentials are
+// taken from the environment, e.g. TELEGRAM_BOT_TOKEN.
+type TargetConfig struct {
+	Name     string `yaml:"name"`
+	Provider string `yaml:"provider"`
+
+	TelegramChatIDs   []int64  `yaml:"telegram_chat_ids"`
+	DiscordChannelIDs []string `yaml:"discord_channel_ids"`
+	SlackChannelIDs   []string `yaml:"slack_channel_ids"`
+	SlackWebhookURL   string   `yaml:"slack_webhook_url"`
+	EmailTo           []string `yaml:"email_to"`
+
+	// Webhook targets fall back to WEBHOOK_SECRET and WEBHOOK_TIMEOUT when unset,
+	// webhook_headers are added to WEBHOOK_HEADERS
+	WebhookURLs    []string          `yaml:"webhook_urls"`
+	WebhookSecret  string            `yaml:"webhook_secret"`
+	WebhookHeaders map[string]string `yaml:"webhook_headers"`
+	WebhookTimeout time.Duration     `yaml:"webhook_timeout"`
+
+	TeamsWebhookURLs     []string `yaml:"teams_webhook_urls"`
+	MattermostChannelIDs []string `yaml:"mattermost_channel_ids"`
+	MattermostWebhookURL string   `yaml:"mattermost_webhook_url"`
+
+	// IANA timezone digests are scheduled and shown in, UTC by default
+	Timezone string `yaml:"timezone"`
+}
+
+func (t TargetConfig) postsToSlackChannels() bool {
+	return t.Provider == AlertProviderSlack && t.SlackWebhookURL == ""
+}
+
+func (t TargetConfig) postsToMattermostChannels() bool {
+	return t.Provider == AlertProviderMattermost && t.MattermostWebhookURL == ""
+}
+
+func (t TargetConfig) signsWithWebhookSecret() bool {
+	return t.Provider == AlertProviderWebhook && t.WebhookSecret == ""
+}
+
+// RouteConfig sends alerts matching all of its conditions to its targets.
+// Routes are evaluated in order and the first matching route wins, unless it
+// sets Continue. Alerts matching no route go to the ALERT_PROVIDER targets.
+// Templates of the route replace the provider templates for its targets.
+type RouteConfig struct {
+	Name      string                    `yaml:"name"`
+	Match     MatchConfig               `yaml:"match"`
+	Targets   []string                  `yaml:"targets"`
+	Continue  bool                      `yaml:"continue"`
+	Templates map[string]TemplateConfig `yaml:"templates"` // Message templates by provider
+}
+
+// MatchConfig holds patterns matched against alerts. Patterns are globs
+// ("*" and "?" wildcards) or, when prefixed with "re:", regular expressions.
+// Empty patterns match anything.
+type MatchConfig struct {
+	Service     string            `yaml:"service"`
+	Operation   string            `yaml:"operation"`
+	Code        string            `yaml:"code"`
+	Environment string            `yaml:"environment"`
+	Details     map[string]string `yaml:"details"` // Patterns by detail key
+}
+
+var conditionTypeChoices = []string{"count", "rate", "distinct_users", "percent_increase"}
+
+func loadAlertingConfig(path string) (AlertingConfig, error) {
+	var alerting AlertingConfig
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return alerting, fmt.Errorf("loadAlertingConfig: %w", err)
+	}
+
+	decoder := yaml.NewDecoder(bytes.NewReader(data))
+	decoder.KnownFields(true)
+
+	err = decoder.Decode(&alerting)
+	if err != nil {
+		return alerting, fmt.Errorf("loadAlertingConfig: %s: %w", path, err)
+	}
+
+	return alerting, nil
+}
+
+// DefaultTargets returns the targets of the ALERT_PROVIDER providers, named after them.
+func (cfg Config) DefaultTargets() []TargetConfig {
+	targets := make([]TargetConfig, 0, len(cfg.AlertProviders))
+	for _, provider := range cfg.AlertProviders {
+		targets = append(targets, TargetConfig{
+			Name:              provider,
+			Provider:          provider,
+			TelegramChatIDs:   cfg.TelegramsChatIDs,
+			DiscordChannelIDs: cfg.DiscordChannelIDs,
+			SlackChannelIDs:   cfg.SlackChannelIDs,
+			SlackWebhookURL:   cfg.SlackWebhookURL,
+			EmailTo:           cfg.EmailTo,
+			WebhookURLs:       cfg.WebhookURLs,
+
+			TeamsWebhookURLs:     cfg.TeamsWebhookURLs,
+			MattermostChannelIDs: cfg.MattermostChannelIDs,
+			MattermostWebhookURL: cfg.MattermostWebhookURL,
+		})
+	}
+	return targets
+}
+
+func (cfg Config) validateAlerting() error {
+	names := make([]string, 0, len(cfg.AlertProviders)+len(cfg.Alerting.Targets))
+	names = append(names, cfg.AlertProviders...)
+
+	for _, t := range cfg.Alerting.Targets {
+		if t.Name == "" {
+			return fmt.Errorf("Config.validateAlerting: target name is required")
+		}
+		if slices.Contains(names, t.Name) {
+			return fmt.Errorf("Config.validateAlerting: duplicate target name: %q", t.Name)
+		}
+		names = append(names, t.Name)
+
+		_, err := time.LoadLocation(t.Timezone)
+		if err != nil {
+			return fmt.Errorf("Config.validateAlerting: target %q: invalid timezone: %w", t.Name, err)
+		}
+
+		switch t.Provider {
+		case AlertProviderTelegram:
+			if len(t.TelegramChatIDs) == 0 {
+				return fmt.Errorf("Config.validateAlerting: target %q: telegram_chat_ids is required", t.Name)
+			}
+		case AlertProviderDiscord:
+			if len(t.DiscordChannelIDs) == 0 {
+				return fmt.Errorf("Config.validateAlerting: target %q: discord_channel_ids is required", t.Name)
+			}
+		case AlertProviderSlack:
+			if len(t.SlackChannelIDs) == 0 && t.SlackWebhookURL == "" {
+				return fmt.Errorf("Config.validateAlerting: target %q: slack_channel_ids or slack_webhook_url is required", t.Name)
+			}
+		case AlertProviderEmail:
+			if len(t.EmailTo) == 0 {
+				return fmt.Errorf("Config.validateAlerting: target %q: email_to is required", t.Name)
+			}
+		case AlertProviderWebhook:
+			if len(t.WebhookURLs) == 0 {
+				return fmt.Errorf("Config.validateAlerting: target %q: webhook_urls is required", t.Name)
+			}
+			if t.WebhookTimeout < 0 {
+				return fmt.Errorf("Config.validateAlerting: target %q: webhook_timeout must be positive", t.Name)
+			}
+		case AlertProviderTeams:
+			if len(t.TeamsWebhookURLs) == 0 {
+				return fmt.Errorf("Config.validateAlerting: target %q: teams_webhook_urls is required", t.Name)
+			}
+		case AlertProviderMattermost:
+			if len(t.MattermostChannelIDs) == 0 && t.MattermostWebhookURL == "" {
+				return fmt.Errorf("Config.validateAlerting: target %q: mattermost_channel_ids or mattermost_webhook_url is required", t.Name)
+			}
+		default:
+			return fmt.Errorf("Config.validateAlerting: target %q: invalid provider: %q", t.Name, t.Provider)
+		}
+	}
+
+	conditions := make([]string, 0, len(cfg.Alerting.Conditions))
+	for i, c := range cfg.Alerting.Conditions {
+		if c.Name == "" {
+			return fmt.Errorf("Config.validateAlerting: condition %d: name is required", i)
+		}
+		if slices.Contains(conditions, c.Name) {
+			return fmt.Errorf("Config.validateAlerting: duplicate condition name: %q", c.Name)
+		}
+		conditions = append(conditions, c.Name)
+
+		if !slices.Contains(conditionTypeChoices, c.Type) {
+			return fmt.Errorf("Config.validateAlerting: condition %q: type must be one of %v", c.Name, conditionTypeChoices)
+		}
+		if c.Window <= 0 || c.Window%time.Minute != 0 {
+			return fmt.Errorf("Config.validateAlerting: condition %q: window must be a positive multiple of a minute", c.Name)
+		}
+		if c.Threshold <= 0 {
+			return fmt.Errorf("Config.validateAlerting: condition %q: threshold must be positive", c.Name)
+		}
+		if c.MinCount < 0 {
+			return fmt.Errorf("Config.validateAlerting: condition %q: min_count must not be negative", c.Name)
+		}
+	}
+
+	s := cfg.Alerting.Spikes
+	if s.Window < 0 || s.Window%time.Minute != 0 {
+		return fmt.Errorf("Config.validateAlerting: spikes: window must be a multiple of a minute")
+	}
+	if s.Deviations < 0 || s.MinCount < 0 || s.Days < 0 || s.Cooldown < 0 {
+		return fmt.Errorf("Config.validateAlerting: spikes: deviations, min_count, days and cooldown must not be negative")
+	}
+
+	digests := make([]string, 0, len(cfg.Alerting.Digests))
+	for i, d := range cfg.Alerting.Digests {
+		if d.Name == "" {
+			return fmt.Errorf("Config.validateAlerting: digest %d: name is required", i)
+		}
+		if slices.Contains(digests, d.Name) {
+			return fmt.Errorf("Config.validateAlerting: duplicate digest name: %q", d.Name)
+		}
+		digests = append(digests, d.Name)
+
+		_, err := cron.ParseStandard(d.Schedule)
+		if err != nil {
+			return fmt.Errorf("Config.validateAlerting: digest %q: invalid schedule: %w", d.Name, err)
+		}
+		if d.Period < 0 || d.Period%time.Minute != 0 {
+			return fmt.Errorf("Config.validateAlerting: digest %q: period must be a multiple of a minute", d.Name)
+		}
+		if d.Limit < 0 {
+			return fmt.Errorf("Config.validateAlerting: digest %q: limit must not be negative", d.Name)
+		}
+		if len(d.Targets) == 0 {
+			return fmt.Errorf("Config.validateAlerting: digest %q: targets are required", d.Name)
+		}
+		for _, target := range d.Targets {
+			if !slices.Contains(names, target) {
+				return fmt.Errorf("Config.validateAlerting: digest %q: unknown target: %q", d.Name, target)
+			}
+		}
+	}
+
+	err := validateTemplates(cfg.Alerting.Templates)
+	if err != nil {
+		return fmt.Errorf("Config.validateAlerting: %w", err)
+	}
+
+	for i, r := range cfg.Alerting.Routes {
+		err = validateTemplates(r.Templates)
+		if err != nil {
+			return fmt.Errorf("Config.validateAlerting: route %d (%s): %w", i, r.Name, err)
+		}
+		if len(r.Targets) == 0 {
+			return fmt.Errorf("Config.validateAlerting: route %d (%s): targets are required", i, r.Name)
+		}
+		for _, target := range r.Targets {
+			if !slices.Contains(names, target) {
+				return fmt.Errorf("Config.validateAlerting: route %d (%s): unknown target: %q", i, r.Name, target)
+			}
+		}
+	}
+
+	return nil
+}
+
+// validateTemplates checks the providers of the templates. The templates
+// themselves are parsed and validated when the notifiers are created.
+func validateTemplates(templates map[string]TemplateConfig) error {
+	for provider := range templates {
+		if provider == AlertProviderWebhook {
+			return fmt.Errorf("validateTemplates: webhook payloads can not be templated")
+		}
+		if !slices.Contains(alertProviderChoices, provider) {
+			return fmt.Errorf("validateTemplates: invalid provider: %q", provider)
+		}
+	}
+	return nil
+}
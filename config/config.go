@@ -2,13 +2,37 @@ package config
 
 import (
 	"fmt"
+	"slices"
+	"time"
 
 	"github.com/ilyakaznacheev/cleanenv"
 )
 
 const (
-	AlertProviderDiscord  = "discord"
-	AlertProviderTelegram = "telegram"
+	AlertProviderDiscord    = "discord"
+	AlertProviderTelegram   = "telegram"
+	AlertProviderSlack      = "slack"
+	AlertProviderEmail      = "email"
+	AlertProviderWebhook    = "webhook"
+	AlertProviderTeams      = "teams"
+	AlertProviderMattermost = "mattermost"
+)
+
+var alertProviderChoices = []string{
+	AlertProviderDiscord, AlertProviderTelegram, AlertProviderSlack, AlertProviderEmail, AlertProviderWebhook,
+	AlertProviderTeams, AlertProviderMattermost,
+}
+
+const (
+	SMTPSecurityStartTLS = "starttls" // Upgrade a plain connection with STARTTLS
+	SMTPSecurityTLS      = "tls"      // Implicit TLS, usually on port 465
+	SMTPSecurityNone     = "none"     // Plain connection, for local relays only
+)
+
+const (
+	AlertQueueFullPolicyBlock    = "block"
+	AlertQueueFullPolicyDrop     = "drop"
+	AlertQueueFullPolicyCoalesce = "coalesce"
 )
 
 type Config struct {
@@ -17,20 +41,92 @@ type Config struct {
 	GrpcHost string `env:"GRPC_HOST"    env-default:"localhost"`
 	GrpcPort string `env:"GRPC_PORT"    env-default:"5001"`
 
+	HttpHost string `env:"HTTP_HOST"    env-default:"localhost"`
+	HttpPort string `env:"HTTP_PORT"    env-default:"8080"`
+
+	// API keys accepted from clients of both gRPC and HTTP APIs.
+	// Authentication is disabled if none are set
+	APIKeys []string `env:"API_KEYS"`
+
+	// Maximum number of errors accepted in a single SendErrors call
+	BatchMaxSize int `env:"BATCH_MAX_SIZE" env-default:"1000"`
+
+	// StreamErrors writes received errors to the store in batches of up to
+	// StreamBatchSize, flushing incomplete batches every StreamFlushInterval
+	StreamBatchSize     int           `env:"STREAM_BATCH_SIZE"     env-default:"100"`
+	StreamFlushInterval time.Duration `env:"STREAM_FLUSH_INTERVAL" env-default:"1s"`
+
 	PostgresHost     string `env:"POSTGRES_HOST"     env-default:"localhost"`
 	PostgresPort     string `env:"POSTGRES_PORT"     env-default:"5432"`
 	PostgresUser     string `env:"POSTGRES_USER"     env-default:"postgres"`
 	PostgresPassword string `env:"POSTGRES_PASSWORD" env-required:"true"`
 	PostgresDatabase string `env:"POSTGRES_DATABASE" env-default:"sentinel"`
 
-	AlertProvider        string `env:"ALERT_PROVIDER"         env-required:"true"`
-	AlertCooldownMinutes int    `env:"ALERT_COOLDOWN_MINUTES" env-default:"5"`
+	// Comma separated list of providers alerts are sent to, e.g. "telegram,discord"
+	AlertProviders       []string `env:"ALERT_PROVIDER"         env-required:"true"`
+	AlertCooldownMinutes int      `env:"ALERT_COOLDOWN_MINUTES" env-default:"5"`
+
+	// Alerts are delivered from a persistent outbox. Failed deliveries are retried
+	// with exponential backoff until AlertMaxAttempts is reached
+	AlertPollInterval   time.Duration `env:"ALERT_POLL_INTERVAL"     env-default:"1s"`
+	AlertMaxAttempts    int           `env:"ALERT_MAX_ATTEMPTS"      env-default:"10"`
+	AlertRetryBaseDelay time.Duration `env:"ALERT_RETRY_BASE_DELAY"  env-default:"5s"`
+	AlertRetryMaxDelay  time.Duration `env:"ALERT_RETRY_MAX_DELAY"   env-default:"10m"`
+
+	// Sent and suppressed alerts are deleted from the outbox after AlertOutboxRetention
+	AlertOutboxRetention time.Duration `env:"ALERT_OUTBOX_RETENTION" env-default:"168h"`
+
+	// Claimed alerts wait in a queue of AlertQueueSize for one of AlertWorkers workers.
+	// AlertQueueFullPolicy decides what happens to alerts when the queue is full
+	AlertWorkers         int    `env:"ALERT_WORKERS"           env-default:"4"`
+	AlertQueueSize       int    `env:"ALERT_QUEUE_SIZE"        env-default:"1000"`
+	AlertQueueFullPolicy string `env:"ALERT_QUEUE_FULL_POLICY" env-default:"block"`
+
+	// Extra message normalization rules in the "<placeholder>=<regexp>" form,
+	// applied before the built-in ones (e.g. "<order>=ORD-[0-9]+;<sku>=SKU-\w+")
+	NormalizeRules []string `env:"NORMALIZE_RULES" env-separator:";"`
 
 	TelegramBotToken string  `env:"TELEGRAM_BOT_TOKEN"`
 	TelegramsChatIDs []int64 `env:"TELEGRAM_CHAT_IDS"`
 
 	DiscordBotToken   string   `env:"DISCORD_BOT_TOKEN"`
 	DiscordChannelIDs []string `env:"DISCORD_CHANNEL_IDS"`
+
+	// Slack alerts are posted either with a bot token to SLACK_CHANNEL_IDS
+	// or to an incoming webhook
+	SlackBotToken   string   `env:"SLACK_BOT_TOKEN"`
+	SlackChannelIDs []string `env:"SLACK_CHANNEL_IDS"`
+	SlackWebhookURL string   `env:"SLACK_WEBHOOK_URL"`
+
+	// SMTP_SECURITY is one of "starttls", "tls" (implicit TLS) and "none"
+	SMTPHost     string   `env:"SMTP_HOST"`
+	SMTPPort     string   `env:"SMTP_PORT"     env-default:"587"`
+	SMTPUsername string   `env:"SMTP_USERNAME"`
+	SMTPPassword string   `env:"SMTP_PASSWORD"`
+	SMTPFrom     string   `env:"SMTP_FROM"`
+	SMTPSecurity string   `env:"SMTP_SECURITY" env-default:"starttls"`
+	EmailTo      []string `env:"EMAIL_TO"`
+
+	// Webhook alerts are signed with WEBHOOK_SECRET, see notifier.WebhookSignatureHeader.
+	// It is required unless every webhook target has a secret of its own.
+	// WEBHOOK_HEADERS holds extra request headers, e.g. "Authorization:Bearer xyz"
+	WebhookURLs    []string          `env:"WEBHOOK_URLS"`
+	WebhookSecret  string            `env:"WEBHOOK_SECRET"`
+	WebhookHeaders map[string]string `env:"WEBHOOK_HEADERS"`
+	WebhookTimeout time.Duration     `env:"WEBHOOK_TIMEOUT" env-default:"10s"`
+
+	TeamsWebhookURLs []string `env:"TEAMS_WEBHOOK_URLS"`
+
+	// Mattermost alerts are posted either with a bot token to MATTERMOST_CHANNEL_IDS
+	// of the MATTERMOST_URL server or to an incoming webhook
+	MattermostURL        string   `env:"MATTERMOST_URL"`
+	MattermostBotToken   string   `env:"MATTERMOST_BOT_TOKEN"`
+	MattermostChannelIDs []string `env:"MATTERMOST_CHANNEL_IDS"`
+	MattermostWebhookURL string   `env:"MATTERMOST_WEBHOOK_URL"`
+
+	// Path to the YAML file with alert targets and routing rules, see AlertingConfig
+	AlertingConfigFile string `env:"ALERTING_CONFIG"`
+	Alerting           AlertingConfig
 }
 
 func LoadConfig() (Config, error) {
@@ -41,6 +137,13 @@ func LoadConfig() (Config, error) {
 		return cfg, fmt.Errorf("LoadConfig: %w", err)
 	}
 
+	if cfg.AlertingConfigFile != "" {
+		cfg.Alerting, err = loadAlertingConfig(cfg.AlertingConfigFile)
+		if err != nil {
+			return cfg, fmt.Errorf("LoadConfig: %w", err)
+		}
+	}
+
 	err = cfg.validate()
 	if err != nil {
 		return cfg, fmt.Errorf("LoadConfig: %w", err)
@@ -50,29 +153,155 @@ func LoadConfig() (Config, error) {
 }
 
 func (cfg Config) validate() error {
-	// Validate provider type
-	if cfg.AlertProvider != AlertProviderDiscord && cfg.AlertProvider != AlertProviderTelegram {
-		return fmt.Errorf("Config.validate: invalid alert provider: %q. Choices are: %q, %q",
-			cfg.AlertProvider, AlertProviderDiscord, AlertProviderTelegram)
+	if cfg.BatchMaxSize <= 0 {
+		return fmt.Errorf("Config.validate: BATCH_MAX_SIZE must be positive")
+	}
+	if cfg.StreamBatchSize <= 0 {
+		return fmt.Errorf("Config.validate: STREAM_BATCH_SIZE must be positive")
+	}
+	if cfg.StreamFlushInterval <= 0 {
+		return fmt.Errorf("Config.validate: STREAM_FLUSH_INTERVAL must be positive")
 	}
 
-	// Validate token and chat/channel IDs based on provider
-	if cfg.AlertProvider == AlertProviderTelegram {
-		if cfg.TelegramBotToken == "" {
-			return fmt.Errorf("Config.validate: TELEGRAM_BOT_TOKEN is required for Telegram alert provider")
+	if cfg.AlertPollInterval <= 0 {
+		return fmt.Errorf("Config.validate: ALERT_POLL_INTERVAL must be positive")
+	}
+	if cfg.AlertOutboxRetention <= 0 {
+		return fmt.Errorf("Config.validate: ALERT_OUTBOX_RETENTION must be positive")
+	}
+	if cfg.AlertMaxAttempts <= 0 {
+		return fmt.Errorf("Config.validate: ALERT_MAX_ATTEMPTS must be positive")
+	}
+	if cfg.AlertWorkers <= 0 {
+		return fmt.Errorf("Config.validate: ALERT_WORKERS must be positive")
+	}
+	if cfg.AlertQueueSize <= 0 {
+		return fmt.Errorf("Config.validate: ALERT_QUEUE_SIZE must be positive")
+	}
+	switch cfg.AlertQueueFullPolicy {
+	case AlertQueueFullPolicyBlock, AlertQueueFullPolicyDrop, AlertQueueFullPolicyCoalesce:
+	default:
+		return fmt.Errorf("Config.validate: invalid alert queue full policy: %q. Choices are: %q, %q, %q",
+			cfg.AlertQueueFullPolicy, AlertQueueFullPolicyBlock, AlertQueueFullPolicyDrop, AlertQueueFullPolicyCoalesce)
+	}
+
+	// Validate provider types
+	for i, provider := range cfg.AlertProviders {
+		if !slices.Contains(alertProviderChoices, provider) {
+			return fmt.Errorf("Config.validate: invalid alert provider: %q. Choices are: %q",
+				provider, alertProviderChoices)
 		}
+		if slices.Contains(cfg.AlertProviders[:i], provider) {
+			return fmt.Errorf("Config.validate: duplicate alert provider: %q", provider)
+		}
+	}
+
+	// Validate token and chat/channel IDs based on providers
+	if cfg.HasAlertProvider(AlertProviderTelegram) {
 		if len(cfg.TelegramsChatIDs) == 0 {
 			return fmt.Errorf("Config.validate: TELEGRAM_CHAT_IDS is required for Telegram alert provider")
 		}
 	}
-	if cfg.AlertProvider == AlertProviderDiscord {
-		if cfg.DiscordBotToken == "" {
-			return fmt.Errorf("Config.validate: DISCORD_BOT_TOKEN is required for Discord alert provider")
-		}
+	if cfg.HasAlertProvider(AlertProviderDiscord) {
 		if len(cfg.DiscordChannelIDs) == 0 {
 			return fmt.Errorf("Config.validate: DISCORD_CHANNEL_IDS is required for Discord alert provider")
 		}
 	}
+	if cfg.HasAlertProvider(AlertProviderSlack) {
+		if cfg.SlackWebhookURL == "" && len(cfg.SlackChannelIDs) == 0 {
+			return fmt.Errorf("Config.validate: SLACK_CHANNEL_IDS or SLACK_WEBHOOK_URL is required for Slack alert provider")
+		}
+	}
+	if cfg.HasAlertProvider(AlertProviderEmail) {
+		if len(cfg.EmailTo) == 0 {
+			return fmt.Errorf("Config.validate: EMAIL_TO is required for Email alert provider")
+		}
+	}
+	if cfg.HasAlertProvider(AlertProviderWebhook) {
+		if len(cfg.WebhookURLs) == 0 {
+			return fmt.Errorf("Config.validate: WEBHOOK_URLS is required for Webhook alert provider")
+		}
+		if cfg.WebhookTimeout <= 0 {
+			return fmt.Errorf("Config.validate: WEBHOOK_TIMEOUT must be positive")
+		}
+	}
+	if cfg.HasAlertProvider(AlertProviderTeams) {
+		if len(cfg.TeamsWebhookURLs) == 0 {
+			return fmt.Errorf("Config.validate: TEAMS_WEBHOOK_URLS is required for Teams alert provider")
+		}
+	}
+	if cfg.HasAlertProvider(AlertProviderMattermost) {
+		if cfg.MattermostWebhookURL == "" && len(cfg.MattermostChannelIDs) == 0 {
+			return fmt.Errorf("Config.validate: MATTERMOST_CHANNEL_IDS or MATTERMOST_WEBHOOK_URL is required for Mattermost alert provider")
+		}
+	}
+	if cfg.UsesProvider(AlertProviderTelegram) && cfg.TelegramBotToken == "" {
+		return fmt.Errorf("Config.validate: TELEGRAM_BOT_TOKEN is required for Telegram alert provider")
+	}
+	if cfg.UsesProvider(AlertProviderDiscord) && cfg.DiscordBotToken == "" {
+		return fmt.Errorf("Config.validate: DISCORD_BOT_TOKEN is required for Discord alert provider")
+	}
+	if cfg.UsesProvider(AlertProviderEmail) {
+		if cfg.SMTPHost == "" || cfg.SMTPFrom == "" {
+			return fmt.Errorf("Config.validate: SMTP_HOST and SMTP_FROM are required for Email alert provider")
+		}
+		switch cfg.SMTPSecurity {
+		case SMTPSecurityStartTLS, SMTPSecurityTLS, SMTPSecurityNone:
+		default:
+			return fmt.Errorf("Config.validate: invalid SMTP security: %q. Choices are: %q, %q, %q",
+				cfg.SMTPSecurity, SMTPSecurityStartTLS, SMTPSecurityTLS, SMTPSecurityNone)
+		}
+	}
+	if cfg.usesSlackChannels() && cfg.SlackBotToken == "" {
+		return fmt.Errorf("Config.validate: SLACK_BOT_TOKEN is required to post to Slack channels")
+	}
+	if cfg.usesWebhookSecret() && cfg.WebhookSecret == "" {
+		return fmt.Errorf("Config.validate: WEBHOOK_SECRET is required to sign webhook alerts")
+	}
+	if cfg.usesMattermostChannels() && (cfg.MattermostURL == "" || cfg.MattermostBotToken == "") {
+		return fmt.Errorf("Config.validate: MATTERMOST_URL and MATTERMOST_BOT_TOKEN are required to post to Mattermost channels")
+	}
+
+	err := cfg.validateAlerting()
+	if err != nil {
+		return fmt.Errorf("Config.validate: %w", err)
+	}
 
 	return nil
 }
+
+func (cfg Config) HasAlertProvider(provider string) bool {
+	return slices.Contains(cfg.AlertProviders, provider)
+}
+
+// UsesProvider reports whether any of the ALERT_PROVIDER providers or alerting
+// config targets uses the provider.
+func (cfg Config) UsesProvider(provider string) bool {
+	if cfg.HasAlertProvider(provider) {
+		return true
+	}
+	return slices.ContainsFunc(cfg.Alerting.Targets, func(t TargetConfig) bool {
+		return t.Provider == provider
+	})
+}
+
+// usesSlackChannels reports whether any Slack target posts with the bot token
+// rather than to an incoming webhook.
+func (cfg Config) usesSlackChannels() bool {
+	return slices.ContainsFunc(cfg.DefaultTargets(), TargetConfig.postsToSlackChannels) ||
+		slices.ContainsFunc(cfg.Alerting.Targets, TargetConfig.postsToSlackChannels)
+}
+
+// usesWebhookSecret reports whether any webhook target signs its requests
+// with WEBHOOK_SECRET rather than with a secret of its own.
+func (cfg Config) usesWebhookSecret() bool {
+	return slices.ContainsFunc(cfg.DefaultTargets(), TargetConfig.signsWithWebhookSecret) ||
+		slices.ContainsFunc(cfg.Alerting.Targets, TargetConfig.signsWithWebhookSecret)
+}
+
+// usesMattermostChannels reports whether any Mattermost target posts with the
+// bot token rather than to an incoming webhook.
+func (cfg Config) usesMattermostChannels() bool {
+	return slices.ContainsFunc(cfg.DefaultTargets(), TargetConfig.postsToMattermostChannels) ||
+		slices.ContainsFunc(cfg.Alerting.Targets, TargetConfig.postsToMattermostChannels)
+}
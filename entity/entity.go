@@ -11,6 +11,223 @@ type ErrorInfo struct {
 	Service   string
 	Operation string
 
+	// MessageTemplate is the message with variable tokens replaced by placeholders.
+	MessageTemplate string
+
+	// CustomFingerprint is the client supplied grouping override, if any.
+	CustomFingerprint []string
+
+	IssueID     string
+	Fingerprint string
+
 	CreatedAt time.Time
 	Alerted   bool
+
+	// Conditions are the alert conditions the error matches. The error is
+	// counted in their windows when it is saved, they are not stored with it.
+	Conditions []ConditionMatch
+}
+
+// ConditionMatch counts an error in the window of an alert condition.
+type ConditionMatch struct {
+	Condition string
+	User      string // Value of the user detail of the condition, if it counts users
+}
+
+// ConditionWindow identifies the errors of a service, operation and code
+// counted in the window of an alert condition.
+type ConditionWindow struct {
+	Condition string
+	Service   string
+	Operation string
+	Code      string
+}
+
+// ErrorFilter selects stored errors. Zero fields match any error.
+type ErrorFilter struct {
+	Service   string
+	Operation string
+	Code      string
+	From      time.Time // Inclusive
+	To        time.Time // Exclusive
+	Alerted   *bool
+
+	// After continues a listing after the error it points at
+	After *ErrorCursor
+}
+
+// ErrorSearchQuery is a full-text search over stored errors. Empty filters match any error.
+type ErrorSearchQuery struct {
+	Query   string // Words, "quoted phrases", OR and -excluded words
+	Service string
+	From    time.Time // Inclusive
+	To      time.Time // Exclusive
+}
+
+// ErrorSearchResult is an error matching a search.
+type ErrorSearchResult struct {
+	Error ErrorInfo
+	Rank  float64
+	// Snippet is a fragment of the message and details, HTML-escaped, with
+	// the matching words wrapped in <mark> and </mark>
+	Snippet string
+}
+
+// StatsDimension is an attribute error statistics can be grouped by.
+type StatsDimension string
+
+const (
+	StatsDimensionService   StatsDimension = "service"
+	StatsDimensionOperation StatsDimension = "operation"
+	StatsDimensionCode      StatsDimension = "code"
+)
+
+// ErrorStatsQuery selects error counts per time bucket. Empty filters match any error.
+type ErrorStatsQuery struct {
+	Service   string
+	Operation string
+	Code      string
+	From      time.Time // Inclusive, the first bucket starts here
+	To        time.Time // Exclusive
+	Interval  time.Duration
+	GroupBy   []StatsDimension
+}
+
+// ErrorStat is the number of errors in a time bucket. Attributes not grouped by are empty.
+type ErrorStat struct {
+	Bucket    time.Time
+	Service   string
+	Operation string
+	Code      string
+	Count     int64
+}
+
+// ErrorCursor is the position of an error in listings, ordered by
+// creation time and ID, newest first.
+type ErrorCursor struct {
+	CreatedAt time.Time
+	ID        string
+}
+
+type IssueStatus string
+
+const (
+	IssueStatusUnresolved IssueStatus = "unresolved"
+	IssueStatusResolved   IssueStatus = "resolved"
+	IssueStatusIgnored    IssueStatus = "ignored"
+)
+
+// Issue groups errors sharing the same fingerprint.
+type Issue struct {
+	ID          string
+	Fingerprint string
+
+	Code      string
+	Message   string // Message of the first error attached to the issue
+	Service   string
+	Operation string
+
+	Status    IssueStatus
+	FirstSeen time.Time
+	LastSeen  time.Time
+	Count     int64
+
+	ResolvedAt *time.Time // Set while resolved
+	Ignore     IgnoreCondition
+}
+
+// IgnoreCondition limits how long an ignored issue stays ignored. An issue
+// ignored with a zero condition is ignored forever, otherwise it is unresolved
+// again on the first error meeting any of the set limits.
+type IgnoreCondition struct {
+	Until      *time.Time // Errors from this time on unresolve the issue
+	UntilCount int64      // The error making the issue count reach this unresolves the issue
+}
+
+// TopIssuesQuery selects the issues with the most errors in a time range.
+type TopIssuesQuery struct {
+	From    time.Time // Inclusive
+	To      time.Time // Exclusive
+	NewOnly bool      // Only issues first seen in the range
+}
+
+// IssueCount is an issue with the number of its errors in a time range.
+type IssueCount struct {
+	Issue Issue
+	Count int64
+}
+
+// ServiceCount is a number of errors or issues of a service.
+type ServiceCount struct {
+	Service string
+	Count   int64
+}
+
+// ServiceIncrease compares the errors of a service in a period and in the period before.
+type ServiceIncrease struct {
+	Service  string
+	Count    int64
+	Previous int64
+}
+
+// Digest summarizes the errors of a period. Its times are in the timezone of
+// the target it is sent to.
+type Digest struct {
+	Name string
+	From time.Time
+	To   time.Time
+
+	NewIssues  []IssueCount      // Issues first seen in the period, the most errors first
+	TopIssues  []IssueCount      // Issues with the most errors in the period
+	Increases  []ServiceIncrease // Services with the biggest increase of errors over the period before
+	Unresolved []ServiceCount    // Unresolved issues by service, the most first
+
+	UnresolvedTotal int64
+}
+
+// AlertKind tells why an alert is sent.
+type AlertKind string
+
+const (
+	AlertKindError      AlertKind = "error"      // An error was reported
+	AlertKindRegression AlertKind = "regression" // An error was reported for a resolved issue
+	AlertKindCondition  AlertKind = "condition"  // An alert condition holds for the reported errors
+	AlertKindSpike      AlertKind = "spike"      // The error volume of an operation deviates from its baseline
+)
+
+type OutboxStatus string
+
+const (
+	OutboxStatusPending    OutboxStatus = "pending"
+	OutboxStatusSent       OutboxStatus = "sent"
+	OutboxStatusSuppressed OutboxStatus = "suppressed" // Not sent because of the alert cooldown, issue status or alert conditions
+	OutboxStatusDead       OutboxStatus = "dead"       // Gave up after the maximum number of attempts
+	OutboxStatusDropped    OutboxStatus = "dropped"    // Not sent because the alert queue was full
+)
+
+// OutboxEntry is an alert waiting to be delivered for the error it refers to.
+type OutboxEntry struct {
+	ID     string
+	Kind   AlertKind
+	Reason string // Why the alert is sent, for condition and spike alerts
+	Error  ErrorInfo
+
+	Status        OutboxStatus
+	Attempts      int
+	NextAttemptAt time.Time
+	LastError     string
+	Delivered     []string // Targets the alert was delivered to, skipped by retries
+
+	CreatedAt time.Time
+	UpdatedAt time.Time
+}
+
+// Alert is the notification sent about an error.
+type Alert struct {
+	Kind   AlertKind
+	Reason string
+	Error  ErrorInfo
+	Issue  Issue
+
+	Delivered []string // Targets that already got the alert, it is not sent to them again
 }
@@ -26,27 +26,108 @@ var file_service_proto_rawDesc = []byte{
 	0x0a, 0x0d, 0x73, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x12,
 	0x02, 0x70, 0x62, 0x1a, 0x1b, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2f, 0x70, 0x72, 0x6f, 0x74,
 	0x6f, 0x62, 0x75, 0x66, 0x2f, 0x65, 0x6d, 0x70, 0x74, 0x79, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f,
-	0x1a, 0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x32, 0x45, 0x0a,
-	0x0f, 0x53, 0x65, 0x6e, 0x74, 0x69, 0x6e, 0x65, 0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65,
-	0x12, 0x32, 0x0a, 0x09, 0x53, 0x65, 0x6e, 0x64, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x0d, 0x2e,
-	0x70, 0x62, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x16, 0x2e, 0x67,
-	0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45,
-	0x6d, 0x70, 0x74, 0x79, 0x42, 0x07, 0x5a, 0x05, 0x2e, 0x2e, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70,
-	0x72, 0x6f, 0x74, 0x6f, 0x33,
+	0x1a, 0x0b, 0x65, 0x72, 0x72, 0x6f, 0x72, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x61,
+	0x6c, 0x65, 0x72, 0x74, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x73, 0x74, 0x61, 0x74,
+	0x73, 0x2e, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x1a, 0x0b, 0x69, 0x73, 0x73, 0x75, 0x65, 0x2e, 0x70,
+	0x72, 0x6f, 0x74, 0x6f, 0x32, 0xb7, 0x05, 0x0a, 0x0f, 0x53, 0x65, 0x6e, 0x74, 0x69, 0x6e, 0x65,
+	0x6c, 0x53, 0x65, 0x72, 0x76, 0x69, 0x63, 0x65, 0x12, 0x32, 0x0a, 0x09, 0x53, 0x65, 0x6e, 0x64,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72,
+	0x49, 0x6e, 0x66, 0x6f, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e, 0x70, 0x72,
+	0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x12, 0x32, 0x0a, 0x0a,
+	0x53, 0x65, 0x6e, 0x64, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x12, 0x0e, 0x2e, 0x70, 0x62, 0x2e,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x61, 0x74, 0x63, 0x68, 0x1a, 0x14, 0x2e, 0x70, 0x62, 0x2e,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x42, 0x61, 0x74, 0x63, 0x68, 0x52, 0x65, 0x73, 0x75, 0x6c, 0x74,
+	0x12, 0x32, 0x0a, 0x0c, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73,
+	0x12, 0x0d, 0x2e, 0x70, 0x62, 0x2e, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x49, 0x6e, 0x66, 0x6f, 0x1a,
+	0x11, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x72, 0x65, 0x61, 0x6d, 0x53, 0x75, 0x6d, 0x6d, 0x61,
+	0x72, 0x79, 0x28, 0x01, 0x12, 0x3b, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x73, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f,
+	0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x30, 0x0a, 0x08, 0x47, 0x65, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x12, 0x13, 0x2e,
+	0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x52, 0x65, 0x71, 0x75, 0x65,
+	0x73, 0x74, 0x1a, 0x0f, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x74, 0x6f, 0x72, 0x65, 0x64, 0x45, 0x72,
+	0x72, 0x6f, 0x72, 0x12, 0x41, 0x0a, 0x0c, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x73, 0x12, 0x17, 0x2e, 0x70, 0x62, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x45,
+	0x72, 0x72, 0x6f, 0x72, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x18, 0x2e, 0x70,
+	0x62, 0x2e, 0x53, 0x65, 0x61, 0x72, 0x63, 0x68, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x73, 0x52, 0x65,
+	0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x44, 0x0a, 0x0d, 0x47, 0x65, 0x74, 0x45, 0x72, 0x72,
+	0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x12, 0x18, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74,
+	0x45, 0x72, 0x72, 0x6f, 0x72, 0x53, 0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73,
+	0x74, 0x1a, 0x19, 0x2e, 0x70, 0x62, 0x2e, 0x47, 0x65, 0x74, 0x45, 0x72, 0x72, 0x6f, 0x72, 0x53,
+	0x74, 0x61, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73, 0x65, 0x12, 0x32, 0x0a, 0x0c,
+	0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12, 0x17, 0x2e, 0x70,
+	0x62, 0x2e, 0x52, 0x65, 0x73, 0x6f, 0x6c, 0x76, 0x65, 0x49, 0x73, 0x73, 0x75, 0x65, 0x52, 0x65,
+	0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x73, 0x73, 0x75, 0x65,
+	0x12, 0x30, 0x0a, 0x0b, 0x52, 0x65, 0x6f, 0x70, 0x65, 0x6e, 0x49, 0x73, 0x73, 0x75, 0x65, 0x12,
+	0x16, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x6f, 0x70, 0x65, 0x6e, 0x49, 0x73, 0x73, 0x75, 0x65,
+	0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x73, 0x73,
+	0x75, 0x65, 0x12, 0x30, 0x0a, 0x0b, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x49, 0x73, 0x73, 0x75,
+	0x65, 0x12, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x49, 0x67, 0x6e, 0x6f, 0x72, 0x65, 0x49, 0x73, 0x73,
+	0x75, 0x65, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x09, 0x2e, 0x70, 0x62, 0x2e, 0x49,
+	0x73, 0x73, 0x75, 0x65, 0x12, 0x3b, 0x0a, 0x0a, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72,
+	0x74, 0x73, 0x12, 0x15, 0x2e, 0x70, 0x62, 0x2e, 0x4c, 0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72,
+	0x74, 0x73, 0x52, 0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x70, 0x62, 0x2e, 0x4c,
+	0x69, 0x73, 0x74, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x73, 0x52, 0x65, 0x73, 0x70, 0x6f, 0x6e, 0x73,
+	0x65, 0x12, 0x3b, 0x0a, 0x0a, 0x52, 0x65, 0x74, 0x72, 0x79, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x12,
+	0x15, 0x2e, 0x70, 0x62, 0x2e, 0x52, 0x65, 0x74, 0x72, 0x79, 0x41, 0x6c, 0x65, 0x72, 0x74, 0x52,
+	0x65, 0x71, 0x75, 0x65, 0x73, 0x74, 0x1a, 0x16, 0x2e, 0x67, 0x6f, 0x6f, 0x67, 0x6c, 0x65, 0x2e,
+	0x70, 0x72, 0x6f, 0x74, 0x6f, 0x62, 0x75, 0x66, 0x2e, 0x45, 0x6d, 0x70, 0x74, 0x79, 0x42, 0x07,
+	0x5a, 0x05, 0x2e, 0x2e, 0x2f, 0x70, 0x62, 0x62, 0x06, 0x70, 0x72, 0x6f, 0x74, 0x6f, 0x33,
 }
 
 var file_service_proto_goTypes = []any{
-	(*ErrorInfo)(nil),     // 0: pb.ErrorInfo
-	(*emptypb.Empty)(nil), // 1: google.protobuf.Empty
+	(*ErrorInfo)(nil),             // 0: pb.ErrorInfo
+	(*ErrorBatch)(nil),            // 1: pb.ErrorBatch
+	(*ListErrorsRequest)(nil),     // 2: pb.ListErrorsRequest
+	(*GetErrorRequest)(nil),       // 3: pb.GetErrorRequest
+	(*SearchErrorsRequest)(nil),   // 4: pb.SearchErrorsRequest
+	(*GetErrorStatsRequest)(nil),  // 5: pb.GetErrorStatsRequest
+	(*ResolveIssueRequest)(nil),   // 6: pb.ResolveIssueRequest
+	(*ReopenIssueRequest)(nil),    // 7: pb.ReopenIssueRequest
+	(*IgnoreIssueRequest)(nil),    // 8: pb.IgnoreIssueRequest
+	(*ListAlertsRequest)(nil),     // 9: pb.ListAlertsRequest
+	(*RetryAlertRequest)(nil),     // 10: pb.RetryAlertRequest
+	(*emptypb.Empty)(nil),         // 11: google.protobuf.Empty
+	(*ErrorBatchResult)(nil),      // 12: pb.ErrorBatchResult
+	(*StreamSummary)(nil),         // 13: pb.StreamSummary
+	(*ListErrorsResponse)(nil),    // 14: pb.ListErrorsResponse
+	(*StoredError)(nil),           // 15: pb.StoredError
+	(*SearchErrorsResponse)(nil),  // 16: pb.SearchErrorsResponse
+	(*GetErrorStatsResponse)(nil), // 17: pb.GetErrorStatsResponse
+	(*Issue)(nil),                 // 18: pb.Issue
+	(*ListAlertsResponse)(nil),    // 19: pb.ListAlertsResponse
 }
 var file_service_proto_depIdxs = []int32{
-	0, // 0: pb.SentinelService.SendError:input_type -> pb.ErrorInfo
-	1, // 1: pb.SentinelService.SendError:output_type -> google.protobuf.Empty
-	1, // [1:2] is the sub-list for method output_type
-	0, // [0:1] is the sub-list for method input_type
-	0, // [0:0] is the sub-list for extension type_name
-	0, // [0:0] is the sub-list for extension extendee
-	0, // [0:0] is the sub-list for field type_name
+	0,  // 0: pb.SentinelService.SendError:input_type -> pb.ErrorInfo
+	1,  // 1: pb.SentinelService.SendErrors:input_type -> pb.ErrorBatch
+	0,  // 2: pb.SentinelService.StreamErrors:input_type -> pb.ErrorInfo
+	2,  // 3: pb.SentinelService.ListErrors:input_type -> pb.ListErrorsRequest
+	3,  // 4: pb.SentinelService.GetError:input_type -> pb.GetErrorRequest
+	4,  // 5: pb.SentinelService.SearchErrors:input_type -> pb.SearchErrorsRequest
+	5,  // 6: pb.SentinelService.GetErrorStats:input_type -> pb.GetErrorStatsRequest
+	6,  // 7: pb.SentinelService.ResolveIssue:input_type -> pb.ResolveIssueRequest
+	7,  // 8: pb.SentinelService.ReopenIssue:input_type -> pb.ReopenIssueRequest
+	8,  // 9: pb.SentinelService.IgnoreIssue:input_type -> pb.IgnoreIssueRequest
+	9,  // 10: pb.SentinelService.ListAlerts:input_type -> pb.ListAlertsRequest
+	10, // 11: pb.SentinelService.RetryAlert:input_type -> pb.RetryAlertRequest
+	11, // 12: pb.SentinelService.SendError:output_type -> google.protobuf.Empty
+	12, // 13: pb.SentinelService.SendErrors:output_type -> pb.ErrorBatchResult
+	13, // 14: pb.SentinelService.StreamErrors:output_type -> pb.StreamSummary
+	14, // 15: pb.SentinelService.ListErrors:output_type -> pb.ListErrorsResponse
+	15, // 16: pb.SentinelService.GetError:output_type -> pb.StoredError
+	16, // 17: pb.SentinelService.SearchErrors:output_type -> pb.SearchErrorsResponse
+	17, // 18: pb.SentinelService.GetErrorStats:output_type -> pb.GetErrorStatsResponse
+	18, // 19: pb.SentinelService.ResolveIssue:output_type -> pb.Issue
+	18, // 20: pb.SentinelService.ReopenIssue:output_type -> pb.Issue
+	18, // 21: pb.SentinelService.IgnoreIssue:output_type -> pb.Issue
+	19, // 22: pb.SentinelService.ListAlerts:output_type -> pb.ListAlertsResponse
+	11, // 23: pb.SentinelService.RetryAlert:output_type -> google.protobuf.Empty
+	12, // [12:24] is the sub-list for method output_type
+	0,  // [0:12] is the sub-list for method input_type
+	0,  // [0:0] is the sub-list for extension type_name
+	0,  // [0:0] is the sub-list for extension extendee
+	0,  // [0:0] is the sub-list for field type_name
 }
 
 func init() { file_service_proto_init() }
@@ -55,6 +136,9 @@ func file_service_proto_init() {
 		return
 	}
 	file_error_proto_init()
+	file_alert_proto_init()
+	file_stats_proto_init()
+	file_issue_proto_init()
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
@@ -20,7 +20,18 @@ import (
 const _ = grpc.SupportPackageIsVersion7
 
 const (
-	SentinelService_SendError_FullMethodName = "/pb.SentinelService/SendError"
+	SentinelService_SendError_FullMethodName     = "/pb.SentinelService/SendError"
+	SentinelService_SendErrors_FullMethodName    = "/pb.SentinelService/SendErrors"
+	SentinelService_StreamErrors_FullMethodName  = "/pb.SentinelService/StreamErrors"
+	SentinelService_ListErrors_FullMethodName    = "/pb.SentinelService/ListErrors"
+	SentinelService_GetError_FullMethodName      = "/pb.SentinelService/GetError"
+	SentinelService_SearchErrors_FullMethodName  = "/pb.SentinelService/SearchErrors"
+	SentinelService_GetErrorStats_FullMethodName = "/pb.SentinelService/GetErrorStats"
+	SentinelService_ResolveIssue_FullMethodName  = "/pb.SentinelService/ResolveIssue"
+	SentinelService_ReopenIssue_FullMethodName   = "/pb.SentinelService/ReopenIssue"
+	SentinelService_IgnoreIssue_FullMethodName   = "/pb.SentinelService/IgnoreIssue"
+	SentinelService_ListAlerts_FullMethodName    = "/pb.SentinelService/ListAlerts"
+	SentinelService_RetryAlert_FullMethodName    = "/pb.SentinelService/RetryAlert"
 )
 
 // SentinelServiceClient is the client API for SentinelService service.
@@ -28,6 +39,17 @@ const (
 // For semantics around ctx use and closing/ending streaming RPCs, please refer to https://pkg.go.dev/google.golang.org/grpc/?tab=doc#ClientConn.NewStream.
 type SentinelServiceClient interface {
 	SendError(ctx context.Context, in *ErrorInfo, opts ...grpc.CallOption) (*emptypb.Empty, error)
+	SendErrors(ctx context.Context, in *ErrorBatch, opts ...grpc.CallOption) (*ErrorBatchResult, error)
+	StreamErrors(ctx context.Context, opts ...grpc.CallOption) (SentinelService_StreamErrorsClient, error)
+	ListErrors(ctx context.Context, in *ListErrorsRequest, opts ...grpc.CallOption) (*ListErrorsResponse, error)
+	GetError(ctx context.Context, in *GetErrorRequest, opts ...grpc.CallOption) (*StoredError, error)
+	SearchErrors(ctx context.Context, in *SearchErrorsRequest, opts ...grpc.CallOption) (*SearchErrorsResponse, error)
+	GetErrorStats(ctx context.Context, in *GetErrorStatsRequest, opts ...grpc.CallOption) (*GetErrorStatsResponse, error)
+	ResolveIssue(ctx context.Context, in *ResolveIssueRequest, opts ...grpc.CallOption) (*Issue, error)
+	ReopenIssue(ctx context.Context, in *ReopenIssueRequest, opts ...grpc.CallOption) (*Issue, error)
+	IgnoreIssue(ctx context.Context, in *IgnoreIssueRequest, opts ...grpc.CallOption) (*Issue, error)
+	ListAlerts(ctx context.Context, in *ListAlertsRequest, opts ...grpc.CallOption) (*ListAlertsResponse, error)
+	RetryAlert(ctx context.Context, in *RetryAlertRequest, opts ...grpc.CallOption) (*emptypb.Empty, error)
 }
 
 type sentinelServiceClient struct {
@@ -47,11 +69,146 @@ func (c *sentinelServiceClient) SendError(ctx context.Context, in *ErrorInfo, op
 	return out, nil
 }
 
+func (c *sentinelServiceClient) SendErrors(ctx context.Context, in *ErrorBatch, opts ...grpc.CallOption) (*ErrorBatchResult, error) {
+	out := new(ErrorBatchResult)
+	err := c.cc.Invoke(ctx, SentinelService_SendErrors_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) StreamErrors(ctx context.Context, opts ...grpc.CallOption) (SentinelService_StreamErrorsClient, error) {
+	stream, err := c.cc.NewStream(ctx, &SentinelService_ServiceDesc.Streams[0], SentinelService_StreamErrors_FullMethodName, opts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &sentinelServiceStreamErrorsClient{stream}
+	return x, nil
+}
+
+type SentinelService_StreamErrorsClient interface {
+	Send(*ErrorInfo) error
+	CloseAndRecv() (*StreamSummary, error)
+	grpc.ClientStream
+}
+
+type sentinelServiceStreamErrorsClient struct {
+	grpc.ClientStream
+}
+
+func (x *sentinelServiceStreamErrorsClient) Send(m *ErrorInfo) error {
+	return x.ClientStream.SendMsg(m)
+}
+
+func (x *sentinelServiceStreamErrorsClient) CloseAndRecv() (*StreamSummary, error) {
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	m := new(StreamSummary)
+	if err := x.ClientStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func (c *sentinelServiceClient) ListErrors(ctx context.Context, in *ListErrorsRequest, opts ...grpc.CallOption) (*ListErrorsResponse, error) {
+	out := new(ListErrorsResponse)
+	err := c.cc.Invoke(ctx, SentinelService_ListErrors_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) GetError(ctx context.Context, in *GetErrorRequest, opts ...grpc.CallOption) (*StoredError, error) {
+	out := new(StoredError)
+	err := c.cc.Invoke(ctx, SentinelService_GetError_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) SearchErrors(ctx context.Context, in *SearchErrorsRequest, opts ...grpc.CallOption) (*SearchErrorsResponse, error) {
+	out := new(SearchErrorsResponse)
+	err := c.cc.Invoke(ctx, SentinelService_SearchErrors_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) GetErrorStats(ctx context.Context, in *GetErrorStatsRequest, opts ...grpc.CallOption) (*GetErrorStatsResponse, error) {
+	out := new(GetErrorStatsResponse)
+	err := c.cc.Invoke(ctx, SentinelService_GetErrorStats_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) ResolveIssue(ctx context.Context, in *ResolveIssueRequest, opts ...grpc.CallOption) (*Issue, error) {
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, SentinelService_ResolveIssue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) ReopenIssue(ctx context.Context, in *ReopenIssueRequest, opts ...grpc.CallOption) (*Issue, error) {
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, SentinelService_ReopenIssue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) IgnoreIssue(ctx context.Context, in *IgnoreIssueRequest, opts ...grpc.CallOption) (*Issue, error) {
+	out := new(Issue)
+	err := c.cc.Invoke(ctx, SentinelService_IgnoreIssue_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) ListAlerts(ctx context.Context, in *ListAlertsRequest, opts ...grpc.CallOption) (*ListAlertsResponse, error) {
+	out := new(ListAlertsResponse)
+	err := c.cc.Invoke(ctx, SentinelService_ListAlerts_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *sentinelServiceClient) RetryAlert(ctx context.Context, in *RetryAlertRequest, opts ...grpc.CallOption) (*emptypb.Empty, error) {
+	out := new(emptypb.Empty)
+	err := c.cc.Invoke(ctx, SentinelService_RetryAlert_FullMethodName, in, out, opts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // SentinelServiceServer is the server API for SentinelService service.
 // All implementations must embed UnimplementedSentinelServiceServer
 // for forward compatibility
 type SentinelServiceServer interface {
 	SendError(context.Context, *ErrorInfo) (*emptypb.Empty, error)
+	SendErrors(context.Context, *ErrorBatch) (*ErrorBatchResult, error)
+	StreamErrors(SentinelService_StreamErrorsServer) error
+	ListErrors(context.Context, *ListErrorsRequest) (*ListErrorsResponse, error)
+	GetError(context.Context, *GetErrorRequest) (*StoredError, error)
+	SearchErrors(context.Context, *SearchErrorsRequest) (*SearchErrorsResponse, error)
+	GetErrorStats(context.Context, *GetErrorStatsRequest) (*GetErrorStatsResponse, error)
+	ResolveIssue(context.Context, *ResolveIssueRequest) (*Issue, error)
+	ReopenIssue(context.Context, *ReopenIssueRequest) (*Issue, error)
+	IgnoreIssue(context.Context, *IgnoreIssueRequest) (*Issue, error)
+	ListAlerts(context.Context, *ListAlertsRequest) (*ListAlertsResponse, error)
+	RetryAlert(context.Context, *RetryAlertRequest) (*emptypb.Empty, error)
 	mustEmbedUnimplementedSentinelServiceServer()
 }
 
@@ -62,6 +219,39 @@ type UnimplementedSentinelServiceServer struct {
 func (UnimplementedSentinelServiceServer) SendError(context.Context, *ErrorInfo) (*emptypb.Empty, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method SendError not implemented")
 }
+func (UnimplementedSentinelServiceServer) SendErrors(context.Context, *ErrorBatch) (*ErrorBatchResult, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SendErrors not implemented")
+}
+func (UnimplementedSentinelServiceServer) StreamErrors(SentinelService_StreamErrorsServer) error {
+	return status.Errorf(codes.Unimplemented, "method StreamErrors not implemented")
+}
+func (UnimplementedSentinelServiceServer) ListErrors(context.Context, *ListErrorsRequest) (*ListErrorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListErrors not implemented")
+}
+func (UnimplementedSentinelServiceServer) GetError(context.Context, *GetErrorRequest) (*StoredError, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetError not implemented")
+}
+func (UnimplementedSentinelServiceServer) SearchErrors(context.Context, *SearchErrorsRequest) (*SearchErrorsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SearchErrors not implemented")
+}
+func (UnimplementedSentinelServiceServer) GetErrorStats(context.Context, *GetErrorStatsRequest) (*GetErrorStatsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetErrorStats not implemented")
+}
+func (UnimplementedSentinelServiceServer) ResolveIssue(context.Context, *ResolveIssueRequest) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ResolveIssue not implemented")
+}
+func (UnimplementedSentinelServiceServer) ReopenIssue(context.Context, *ReopenIssueRequest) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ReopenIssue not implemented")
+}
+func (UnimplementedSentinelServiceServer) IgnoreIssue(context.Context, *IgnoreIssueRequest) (*Issue, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method IgnoreIssue not implemented")
+}
+func (UnimplementedSentinelServiceServer) ListAlerts(context.Context, *ListAlertsRequest) (*ListAlertsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAlerts not implemented")
+}
+func (UnimplementedSentinelServiceServer) RetryAlert(context.Context, *RetryAlertRequest) (*emptypb.Empty, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RetryAlert not implemented")
+}
 func (UnimplementedSentinelServiceServer) mustEmbedUnimplementedSentinelServiceServer() {}
 
 // UnsafeSentinelServiceServer may be embedded to opt out of forward compatibility for this service.
@@ -93,6 +283,212 @@ func _SentinelService_SendError_Handler(srv interface{}, ctx context.Context, de
 	return interceptor(ctx, in, info, handler)
 }
 
+func _SentinelService_SendErrors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ErrorBatch)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).SendErrors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_SendErrors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).SendErrors(ctx, req.(*ErrorBatch))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_StreamErrors_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(SentinelServiceServer).StreamErrors(&sentinelServiceStreamErrorsServer{stream})
+}
+
+type SentinelService_StreamErrorsServer interface {
+	SendAndClose(*StreamSummary) error
+	Recv() (*ErrorInfo, error)
+	grpc.ServerStream
+}
+
+type sentinelServiceStreamErrorsServer struct {
+	grpc.ServerStream
+}
+
+func (x *sentinelServiceStreamErrorsServer) SendAndClose(m *StreamSummary) error {
+	return x.ServerStream.SendMsg(m)
+}
+
+func (x *sentinelServiceStreamErrorsServer) Recv() (*ErrorInfo, error) {
+	m := new(ErrorInfo)
+	if err := x.ServerStream.RecvMsg(m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+func _SentinelService_ListErrors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListErrorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).ListErrors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_ListErrors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).ListErrors(ctx, req.(*ListErrorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_GetError_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetErrorRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).GetError(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_GetError_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).GetError(ctx, req.(*GetErrorRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_SearchErrors_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SearchErrorsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).SearchErrors(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_SearchErrors_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).SearchErrors(ctx, req.(*SearchErrorsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_GetErrorStats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(GetErrorStatsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).GetErrorStats(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_GetErrorStats_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).GetErrorStats(ctx, req.(*GetErrorStatsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_ResolveIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ResolveIssueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).ResolveIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_ResolveIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).ResolveIssue(ctx, req.(*ResolveIssueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_ReopenIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ReopenIssueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).ReopenIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_ReopenIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).ReopenIssue(ctx, req.(*ReopenIssueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_IgnoreIssue_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(IgnoreIssueRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).IgnoreIssue(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_IgnoreIssue_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).IgnoreIssue(ctx, req.(*IgnoreIssueRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_ListAlerts_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAlertsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).ListAlerts(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_ListAlerts_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).ListAlerts(ctx, req.(*ListAlertsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _SentinelService_RetryAlert_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(RetryAlertRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(SentinelServiceServer).RetryAlert(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: SentinelService_RetryAlert_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(SentinelServiceServer).RetryAlert(ctx, req.(*RetryAlertRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // SentinelService_ServiceDesc is the grpc.ServiceDesc for SentinelService service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -104,7 +500,53 @@ var SentinelService_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "SendError",
 			Handler:    _SentinelService_SendError_Handler,
 		},
+		{
+			MethodName: "SendErrors",
+			Handler:    _SentinelService_SendErrors_Handler,
+		},
+		{
+			MethodName: "ListErrors",
+			Handler:    _SentinelService_ListErrors_Handler,
+		},
+		{
+			MethodName: "GetError",
+			Handler:    _SentinelService_GetError_Handler,
+		},
+		{
+			MethodName: "SearchErrors",
+			Handler:    _SentinelService_SearchErrors_Handler,
+		},
+		{
+			MethodName: "GetErrorStats",
+			Handler:    _SentinelService_GetErrorStats_Handler,
+		},
+		{
+			MethodName: "ResolveIssue",
+			Handler:    _SentinelService_ResolveIssue_Handler,
+		},
+		{
+			MethodName: "ReopenIssue",
+			Handler:    _SentinelService_ReopenIssue_Handler,
+		},
+		{
+			MethodName: "IgnoreIssue",
+			Handler:    _SentinelService_IgnoreIssue_Handler,
+		},
+		{
+			MethodName: "ListAlerts",
+			Handler:    _SentinelService_ListAlerts_Handler,
+		},
+		{
+			MethodName: "RetryAlert",
+			Handler:    _SentinelService_RetryAlert_Handler,
+		},
+	},
+	Streams: []grpc.StreamDesc{
+		{
+			StreamName:    "StreamErrors",
+			Handler:       _SentinelService_StreamErrors_Handler,
+			ClientStreams: true,
+		},
 	},
-	Streams:  []grpc.StreamDesc{},
 	Metadata: "service.proto",
 }
@@ -0,0 +1,59 @@
+package notifier
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+// digestMessageLimit is the length of issue messages in digests.
+const digestMessageLimit = 80
+
+// buildDigestMessage renders the digest as a message with a list field per section.
+func buildDigestMessage(environment string, d entity.Digest) message {
+	const timeLayout = "2006-01-02 15:04 MST"
+
+	unresolved := []string{fmt.Sprintf("%d in total", d.UnresolvedTotal)}
+	for _, c := range d.Unresolved {
+		unresolved = append(unresolved, fmt.Sprintf("%s: %d", c.Service, c.Count))
+	}
+
+	increases := make([]string, 0, len(d.Increases))
+	for i, inc := range d.Increases {
+		change := "new"
+		if inc.Previous > 0 {
+			change = fmt.Sprintf("%+.0f%%", float64(inc.Count-inc.Previous)/float64(inc.Previous)*100)
+		}
+		increases = append(increases, fmt.Sprintf("%d. %s: %d → %d errors (%s)", i+1, inc.Service, inc.Previous, inc.Count, change))
+	}
+
+	return message{
+		Title: fmt.Sprintf("🗞️ Sentinel digest: %s", d.Name),
+		Fields: []field{
+			{Emoji: "🔍", Label: "Environment", Value: environment},
+			{Emoji: "🗓️", Label: "Period", Value: fmt.Sprintf("%s – %s", d.From.Format(timeLayout), d.To.Format(timeLayout))},
+			{Emoji: "🆕", Label: "Top new issues", Lines: digestList(issueLines(d.NewIssues))},
+			{Emoji: "🔥", Label: "Most frequent issues", Lines: digestList(issueLines(d.TopIssues))},
+			{Emoji: "📈", Label: "Biggest increases", Lines: digestList(increases)},
+			{Emoji: "🐞", Label: "Unresolved issues", Lines: digestList(unresolved)},
+		},
+	}
+}
+
+func issueLines(issues []entity.IssueCount) []string {
+	lines := make([]string, 0, len(issues))
+	for i, ic := range issues {
+		lines = append(lines, fmt.Sprintf("%d. %s / %s · %s: %s (%d errors)",
+			i+1, ic.Issue.Service, ic.Issue.Operation, ic.Issue.Code,
+			truncateRunes(digestMessageLimit, strings.Join(strings.Fields(ic.Issue.Message), " ")), ic.Count))
+	}
+	return lines
+}
+
+func digestList(lines []string) []string {
+	if len(lines) == 0 {
+		return []string{"None"}
+	}
+	return lines
+}
@@ -4,43 +4,51 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"slices"
+	"strings"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/code19m/sentinel/entity"
-	"github.com/nikoksr/notify"
-	"github.com/nikoksr/notify/service/discord"
 )
 
+// discordMaxFiles is the number of files Discord accepts in a single message.
+const discordMaxFiles = 10
+
 type discordNotifier struct {
-	notifier    notify.Notifier
+	session     *discordgo.Session
+	channelIDs  []string
 	environment string
+	template    *msgTemplate
 }
 
 func NewDiscordNotifier(token string, channelIDs []string, environment string) (*discordNotifier, error) {
-	d := discord.New()
-	err := d.AuthenticateWithBotToken(token)
+	session, err := discordgo.New("Bot " + token)
 	if err != nil {
 		return nil, fmt.Errorf("NewDiscordNotifier: %w", err)
 	}
-	d.AddReceivers(channelIDs...)
-
-	n := notify.New()
-	n.UseServices(d)
 
 	return &discordNotifier{
-		notifier:    n,
+		session:     session,
+		channelIDs:  channelIDs,
 		environment: environment,
 	}, nil
 }
 
-func (dn *discordNotifier) Notify(ctx context.Context, e entity.ErrorInfo) error {
-	// Build the message title
-	msgTitle := dn.buildMsgTitle()
+func (dn *discordNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	// Values too large for a message are sent as files
+	msg, attachments := buildMessage(dn.environment, a).withAttachments()
 
-	// Build the message body
-	msgBody := dn.buildMsgBody(e)
+	// Build the message title and body, from the user defined template if any
+	msgBody := dn.buildMsgBody(msg, attachments)
+	msgTitle, body, err := dn.template.render(dn.environment, a, dn.buildMsgTitle(msg), strings.Join(msgBody, ""))
+	if err != nil {
+		return fmt.Errorf("discordNotifier.Notify: %w", err)
+	}
+	if dn.template != nil {
+		msgBody = []string{body}
+	}
 
-	// Send the message
-	err := dn.notifier.Send(ctx, msgTitle, msgBody)
+	err = dn.deliver(ctx, msgTitle, msgBody, attachments, a.Delivered)
 	if err != nil {
 		return fmt.Errorf("discordNotifier.Notify: %w", err)
 	}
@@ -48,32 +56,99 @@ func (dn *discordNotifier) Notify(ctx context.Context, e entity.ErrorInfo) error
 	return nil
 }
 
-func (dn *discordNotifier) buildMsgTitle() string {
-	return "**❗ Error from Sentinel**\n"
+func (dn *discordNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	msg, attachments := buildDigestMessage(dn.environment, d).withAttachments()
+
+	err := dn.deliver(ctx, dn.buildMsgTitle(msg), dn.buildMsgBody(msg, attachments), attachments, nil)
+	if err != nil {
+		return fmt.Errorf("discordNotifier.NotifyDigest: %w", err)
+	}
+
+	return nil
+}
+
+// deliver sends the title and body blocks, split to fit the message length
+// limit, and the attachments to all channels not yet delivered to.
+func (dn *discordNotifier) deliver(ctx context.Context, title string, body []string, attachments []attachment, delivered []string) error {
+	texts := splitMessage(append([]string{title + "\n"}, body...), discordMessageLimit, markdownMarkup)
+
+	err := deliverEach(dn.channelIDs, delivered, func(i int) error {
+		return dn.send(ctx, dn.channelIDs[i], texts, attachments)
+	})
+	if err != nil {
+		return fmt.Errorf("discordNotifier.deliver: %w", err)
+	}
+
+	return nil
 }
 
-func (dn *discordNotifier) buildMsgBody(e entity.ErrorInfo) string {
+func (dn *discordNotifier) send(ctx context.Context, channelID string, texts []string, attachments []attachment) error {
+	for _, text := range texts {
+		_, err := dn.session.ChannelMessageSend(channelID, text, discordgo.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("discordNotifier.send: %w", err)
+		}
+	}
+
+	for batch := range slices.Chunk(attachments, discordMaxFiles) {
+		files := make([]*discordgo.File, 0, len(batch))
+		for _, att := range batch {
+			files = append(files, &discordgo.File{
+				Name:        att.Name,
+				ContentType: "text/plain; charset=utf-8",
+				Reader:      bytes.NewReader(att.Content),
+			})
+		}
+
+		_, err := dn.session.ChannelMessageSendComplex(channelID, &discordgo.MessageSend{Files: files}, discordgo.WithContext(ctx))
+		if err != nil {
+			return fmt.Errorf("discordNotifier.send: %w", err)
+		}
+	}
+
+	return nil
+}
+
+func (dn *discordNotifier) WithTemplate(title, body string) (Notifier, error) {
+	t, err := newTemplate(title, body, templateFormat{escape: escapeMarkdown})
+	if err != nil {
+		return nil, fmt.Errorf("discordNotifier.WithTemplate: %w", err)
+	}
+
+	c := *dn
+	c.template = t
+	return &c, nil
+}
+
+func (dn *discordNotifier) buildMsgTitle(msg message) string {
+	return fmt.Sprintf("**%s**\n", escapeMarkdown(msg.Title))
+}
+
+// buildMsgBody returns the body in blocks of complete markup, so that it can be
+// split into several messages.
+func (dn *discordNotifier) buildMsgBody(msg message, attachments []attachment) []string {
 	var buffer bytes.Buffer
 
 	// Main error information
-	buffer.WriteString(fmt.Sprintf("**🔍 Environment:** %s\n", escapeMarkdown(dn.environment)))
-	buffer.WriteString(fmt.Sprintf("**🛠️ Service:** %s\n", escapeMarkdown(e.Service)))
-	buffer.WriteString(fmt.Sprintf("**🔄 Operation:** %s\n", escapeMarkdown(e.Operation)))
-	buffer.WriteString(fmt.Sprintf("**🏷️ Code:** %s\n", escapeMarkdown(e.Code)))
-	buffer.WriteString(fmt.Sprintf("**💬 Message:** %s\n", escapeMarkdown(e.Message)))
+	for _, f := range msg.Fields {
+		buffer.WriteString(fmt.Sprintf("**%s %s:**%s\n", f.Emoji, f.Label, f.text(escapeMarkdown)))
+	}
 
 	// Separator for Details section
-	buffer.WriteString("\n**📋 _Additional details_**\n")
-
-	// Details section with only visible details
-	for k, v := range e.Details {
-		if v != "" {
-			if len(v) > 1000 {
-				v = v[:1000] + "..."
-			}
-			buffer.WriteString(fmt.Sprintf("_%s_: ```%s```", escapeMarkdown(k), v))
-		}
+	if len(msg.Details) > 0 {
+		buffer.WriteString("\n**📋 _Additional details_**\n")
+	}
+
+	blocks := []string{buffer.String()}
+
+	// Details section
+	for _, d := range msg.Details {
+		blocks = append(blocks, fmt.Sprintf("_%s_: ```%s```", escapeMarkdown(d.Key), escapeCodeBlock(d.Value)))
+	}
+
+	if len(attachments) > 0 {
+		blocks = append(blocks, "\n📎 _Full values of long fields are attached as files_\n")
 	}
 
-	return buffer.String()
+	return blocks
 }
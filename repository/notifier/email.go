@@ -0,0 +1,363 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/tls"
+	"encoding/hex"
+	"fmt"
+	"html/template"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/code19m/sentinel/config"
+	"github.com/code19m/sentinel/entity"
+)
+
+const smtpTimeout = 30 * time.Second
+
+// SMTPConfig holds the SMTP server settings of the email notifier.
+type SMTPConfig struct {
+	Host     string
+	Port     string
+	Username string // Authentication is skipped if empty
+	Password string
+	From     string
+	Security string // One of config.SMTPSecurityStartTLS, config.SMTPSecurityTLS and config.SMTPSecurityNone
+}
+
+type emailNotifier struct {
+	smtp        SMTPConfig
+	to          []string
+	environment string
+	template    *msgTemplate
+}
+
+func NewEmailNotifier(cfg SMTPConfig, to []string, environment string) (*emailNotifier, error) {
+	if cfg.Host == "" || cfg.Port == "" || cfg.From == "" {
+		return nil, fmt.Errorf("NewEmailNotifier: host, port and sender are required")
+	}
+	if len(to) == 0 {
+		return nil, fmt.Errorf("NewEmailNotifier: at least one recipient is required")
+	}
+	switch cfg.Security {
+	case config.SMTPSecurityStartTLS, config.SMTPSecurityTLS, config.SMTPSecurityNone:
+	default:
+		return nil, fmt.Errorf("NewEmailNotifier: invalid security mode: %q", cfg.Security)
+	}
+
+	return &emailNotifier{
+		smtp:        cfg,
+		to:          to,
+		environment: environment,
+	}, nil
+}
+
+func (en *emailNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	msg := buildMessage(en.environment, a)
+
+	htmlBody, err := en.buildHTMLBody(msg)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.Notify: %w", err)
+	}
+
+	// The user defined template replaces the subject and the HTML body
+	subject := fmt.Sprintf("%s: %s / %s", msg.Title, a.Error.Service, a.Error.Operation)
+	subject, htmlBody, err = en.template.render(en.environment, a, subject, htmlBody)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.Notify: %w", err)
+	}
+
+	data, err := en.buildMail(msg, subject, htmlBody)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.Notify: %w", err)
+	}
+
+	err = en.send(ctx, data, a.Delivered)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.Notify: %w", err)
+	}
+
+	return nil
+}
+
+func (en *emailNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	msg := buildDigestMessage(en.environment, d)
+
+	htmlBody, err := en.buildHTMLBody(msg)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.NotifyDigest: %w", err)
+	}
+
+	subject := fmt.Sprintf("%s, %s", msg.Title, d.To.Format("2006-01-02"))
+	data, err := en.buildMail(msg, subject, htmlBody)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.NotifyDigest: %w", err)
+	}
+
+	err = en.send(ctx, data, nil)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.NotifyDigest: %w", err)
+	}
+
+	return nil
+}
+
+// buildMail renders a multipart/alternative email with a plain text body and the given HTML body.
+func (en *emailNotifier) buildMail(msg message, subject, htmlBody string) ([]byte, error) {
+	var body bytes.Buffer
+	mw := multipart.NewWriter(&body)
+
+	parts := []struct {
+		contentType string
+		content     string
+	}{
+		{"text/plain; charset=utf-8", en.buildPlainBody(msg)},
+		{"text/html; charset=utf-8", htmlBody},
+	}
+	for _, p := range parts {
+		pw, err := mw.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {p.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("emailNotifier.buildMail: %w", err)
+		}
+
+		qw := quotedprintable.NewWriter(pw)
+		_, err = qw.Write([]byte(p.content))
+		if err != nil {
+			return nil, fmt.Errorf("emailNotifier.buildMail: %w", err)
+		}
+		err = qw.Close()
+		if err != nil {
+			return nil, fmt.Errorf("emailNotifier.buildMail: %w", err)
+		}
+	}
+
+	err := mw.Close()
+	if err != nil {
+		return nil, fmt.Errorf("emailNotifier.buildMail: %w", err)
+	}
+
+	var mail bytes.Buffer
+	headers := []struct{ key, value string }{
+		{"From", en.smtp.From},
+		{"To", strings.Join(en.to, ", ")},
+		{"Subject", mime.QEncoding.Encode("utf-8", subject)},
+		{"Date", time.Now().Format(time.RFC1123Z)},
+		{"Message-ID", en.messageID()},
+		{"MIME-Version", "1.0"},
+		{"Content-Type", fmt.Sprintf("multipart/alternative; boundary=%q", mw.Boundary())},
+	}
+	for _, h := range headers {
+		mail.WriteString(fmt.Sprintf("%s: %s\r\n", h.key, h.value))
+	}
+	mail.WriteString("\r\n")
+	mail.Write(body.Bytes())
+
+	return mail.Bytes(), nil
+}
+
+func (en *emailNotifier) WithTemplate(title, body string) (Notifier, error) {
+	t, err := newTemplate(title, body, templateFormat{htmlBody: true})
+	if err != nil {
+		return nil, fmt.Errorf("emailNotifier.WithTemplate: %w", err)
+	}
+
+	c := *en
+	c.template = t
+	return &c, nil
+}
+
+func (en *emailNotifier) buildPlainBody(msg message) string {
+	var buffer bytes.Buffer
+
+	// Main error information
+	buffer.WriteString(msg.Title + "\n\n")
+	for _, f := range msg.Fields {
+		buffer.WriteString(fmt.Sprintf("%s:%s\n", f.Label, f.text(func(s string) string { return s })))
+	}
+
+	// Details section
+	if len(msg.Details) > 0 {
+		buffer.WriteString("\nAdditional details\n")
+	}
+	for _, d := range msg.Details {
+		buffer.WriteString(fmt.Sprintf("\n%s:\n%s\n", d.Key, d.Value))
+	}
+
+	return buffer.String()
+}
+
+var emailHTMLTemplate = template.Must(template.New("email").Parse(`<!DOCTYPE html>
+<html>
+<body style="font-family: sans-serif;">
+<h2>{{ .Title }}</h2>
+<table cellpadding="4">
+{{- range .Fields }}
+<tr><td valign="top"><b>{{ .Emoji }} {{ .Label }}</b></td><td>
+{{- if .Lines }}{{ range $i, $l := .Lines }}{{ if $i }}<br>{{ end }}{{ $l }}{{ end }}{{ else }}{{ .Value }}{{ end -}}
+</td></tr>
+{{- end }}
+</table>
+{{- if .Details }}
+<h3>📋 <i>Additional details</i></h3>
+{{- end }}
+{{- range .Details }}
+<p><i>{{ .Key }}</i>:</p>
+<pre style="background: #f4f4f4; padding: 8px; white-space: pre-wrap;">{{ .Value }}</pre>
+{{- end }}
+</body>
+</html>
+`))
+
+func (en *emailNotifier) buildHTMLBody(msg message) (string, error) {
+	var buffer bytes.Buffer
+	err := emailHTMLTemplate.Execute(&buffer, msg)
+	if err != nil {
+		return "", fmt.Errorf("emailNotifier.buildHTMLBody: %w", err)
+	}
+	return buffer.String(), nil
+}
+
+func (en *emailNotifier) messageID() string {
+	b := make([]byte, 16)
+	_, _ = rand.Read(b)
+
+	domain := en.smtp.From
+	if at := strings.LastIndex(domain, "@"); at >= 0 {
+		domain = strings.TrimSuffix(domain[at+1:], ">")
+	}
+	return fmt.Sprintf("<%s@%s>", hex.EncodeToString(b), domain)
+}
+
+// send sends the email to the recipients not yet delivered to in one
+// transaction. Recipients rejected by the server are skipped and reported in
+// a *DeliveryError, the others still get the email.
+func (en *emailNotifier) send(ctx context.Context, data []byte, delivered []string) error {
+	var pending []string
+	for _, rcpt := range en.to {
+		if !slices.Contains(delivered, rcpt) {
+			pending = append(pending, rcpt)
+		}
+	}
+	if len(pending) == 0 {
+		return nil
+	}
+
+	addr := net.JoinHostPort(en.smtp.Host, en.smtp.Port)
+	tlsConfig := &tls.Config{ServerName: en.smtp.Host}
+
+	var (
+		conn net.Conn
+		err  error
+	)
+	dialer := &net.Dialer{Timeout: smtpTimeout}
+	if en.smtp.Security == config.SMTPSecurityTLS {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+
+	deadline, ok := ctx.Deadline()
+	if !ok {
+		deadline = time.Now().Add(smtpTimeout)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	c, err := smtp.NewClient(conn, en.smtp.Host)
+	if err != nil {
+		conn.Close()
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+	defer c.Close()
+
+	if en.smtp.Security == config.SMTPSecurityStartTLS {
+		if ok, _ := c.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("emailNotifier.send: server does not support STARTTLS")
+		}
+		err = c.StartTLS(tlsConfig)
+		if err != nil {
+			return fmt.Errorf("emailNotifier.send: %w", err)
+		}
+	}
+
+	if en.smtp.Username != "" {
+		err = c.Auth(smtp.PlainAuth("", en.smtp.Username, en.smtp.Password, en.smtp.Host))
+		if err != nil {
+			return fmt.Errorf("emailNotifier.send: %w", err)
+		}
+	}
+
+	from, err := mailAddress(en.smtp.From)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+	err = c.Mail(from)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+
+	rejected := make(map[string]error)
+	var accepted []string
+	for _, rcpt := range pending {
+		to, err := mailAddress(rcpt)
+		if err == nil {
+			err = c.Rcpt(to)
+		}
+		if err != nil {
+			rejected[rcpt] = err
+			continue
+		}
+		accepted = append(accepted, rcpt)
+	}
+	deliveryErr := &DeliveryError{Errors: rejected, Total: len(pending), Delivered: accepted}
+	if len(accepted) == 0 {
+		return fmt.Errorf("emailNotifier.send: %w", deliveryErr)
+	}
+
+	w, err := c.Data()
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+	_, err = w.Write(data)
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+	err = w.Close()
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+
+	err = c.Quit()
+	if err != nil {
+		return fmt.Errorf("emailNotifier.send: %w", err)
+	}
+
+	if len(rejected) > 0 {
+		return fmt.Errorf("emailNotifier.send: %w", deliveryErr)
+	}
+	return nil
+}
+
+// mailAddress extracts the bare address from "Name <user@example.com>".
+func mailAddress(in string) (string, error) {
+	addr, err := mail.ParseAddress(in)
+	if err != nil {
+		return "", fmt.Errorf("mailAddress: %q: %w", in, err)
+	}
+	return addr.Address, nil
+}
@@ -0,0 +1,252 @@
+package notifier
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"net"
+	"net/mail"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/code19m/sentinel/config"
+	"github.com/code19m/sentinel/entity"
+)
+
+// smtpSession is what the SMTP stub received from a client.
+type smtpSession struct {
+	auth       string // Decoded AUTH PLAIN response
+	from       string
+	recipients []string
+	data       string
+}
+
+// startSMTPStub serves a single SMTP session on a local listener and sends
+// what it received on the returned channel. Recipients in reject are refused.
+func startSMTPStub(t *testing.T, reject ...string) (host, port string, sessions <-chan smtpSession) {
+	t.Helper()
+
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatalf("listen: %v", err)
+	}
+	t.Cleanup(func() { ln.Close() })
+
+	ch := make(chan smtpSession, 1)
+	go func() {
+		conn, err := ln.Accept()
+		if err != nil {
+			return
+		}
+		defer conn.Close()
+
+		s := smtpSession{}
+		defer func() { ch <- s }()
+
+		r := bufio.NewReader(conn)
+		reply := func(format string, args ...any) {
+			fmt.Fprintf(conn, format+"\r\n", args...)
+		}
+
+		reply("220 localhost ESMTP stub")
+		for {
+			line, err := r.ReadString('\n')
+			if err != nil {
+				return
+			}
+			line = strings.TrimRight(line, "\r\n")
+			verb, arg, _ := strings.Cut(line, " ")
+
+			switch strings.ToUpper(verb) {
+			case "EHLO", "HELO":
+				reply("250-localhost")
+				reply("250 AUTH PLAIN")
+			case "AUTH":
+				_, resp, _ := strings.Cut(arg, " ")
+				decoded, err := base64.StdEncoding.DecodeString(resp)
+				if err != nil {
+					reply("501 invalid response")
+					continue
+				}
+				s.auth = string(decoded)
+				reply("235 authenticated")
+			case "MAIL":
+				s.from = strings.TrimPrefix(arg, "FROM:")
+				reply("250 OK")
+			case "RCPT":
+				rcpt := strings.TrimPrefix(arg, "TO:")
+				if slices.Contains(reject, strings.Trim(rcpt, "<>")) {
+					reply("550 no such user")
+					continue
+				}
+				s.recipients = append(s.recipients, rcpt)
+				reply("250 OK")
+			case "DATA":
+				reply("354 go ahead")
+				var data strings.Builder
+				for {
+					line, err := r.ReadString('\n')
+					if err != nil {
+						return
+					}
+					if line == ".\r\n" {
+						break
+					}
+					data.WriteString(strings.TrimPrefix(line, "."))
+				}
+				s.data = data.String()
+				reply("250 queued")
+			case "RSET", "NOOP":
+				reply("250 OK")
+			case "QUIT":
+				reply("221 bye")
+				return
+			default:
+				reply("502 not implemented")
+			}
+		}
+	}()
+
+	host, port, _ = net.SplitHostPort(ln.Addr().String())
+	return host, port, ch
+}
+
+func testAlert() entity.Alert {
+	return entity.Alert{
+		Kind: entity.AlertKindError,
+		Error: entity.ErrorInfo{
+			Code:      "INTERNAL",
+			Message:   "payment failed: <timeout>",
+			Details:   map[string]string{"order_id": "42"},
+			Service:   "billing",
+			Operation: "Charge",
+		},
+	}
+}
+
+func TestEmailNotifierNotify(t *testing.T) {
+	host, port, sessions := startSMTPStub(t)
+
+	en, err := NewEmailNotifier(SMTPConfig{
+		Host:     host,
+		Port:     port,
+		Username: "sentinel",
+		Password: "secret",
+		From:     "Sentinel <sentinel@example.com>",
+		Security: config.SMTPSecurityNone,
+	}, []string{"ops@example.com", "Dev Team <dev@example.com>"}, "production")
+	if err != nil {
+		t.Fatalf("NewEmailNotifier: %v", err)
+	}
+
+	err = en.Notify(context.Background(), testAlert())
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	s := <-sessions
+
+	if s.auth != "\x00sentinel\x00secret" {
+		t.Errorf("AUTH PLAIN = %q, want %q", s.auth, "\x00sentinel\x00secret")
+	}
+	if s.from != "<sentinel@example.com>" {
+		t.Errorf("MAIL FROM = %q, want %q", s.from, "<sentinel@example.com>")
+	}
+	wantRecipients := []string{"<ops@example.com>", "<dev@example.com>"}
+	if !slices.Equal(s.recipients, wantRecipients) {
+		t.Errorf("RCPT TO = %v, want %v", s.recipients, wantRecipients)
+	}
+
+	msg, err := mail.ReadMessage(strings.NewReader(s.data))
+	if err != nil {
+		t.Fatalf("ReadMessage: %v", err)
+	}
+	subject, err := new(mime.WordDecoder).DecodeHeader(msg.Header.Get("Subject"))
+	if err != nil {
+		t.Fatalf("decode subject: %v", err)
+	}
+	if !strings.Contains(subject, "billing / Charge") {
+		t.Errorf("Subject = %q, want the service and operation", subject)
+	}
+
+	mediaType, params, err := mime.ParseMediaType(msg.Header.Get("Content-Type"))
+	if err != nil {
+		t.Fatalf("parse Content-Type: %v", err)
+	}
+	if mediaType != "multipart/alternative" {
+		t.Fatalf("Content-Type = %q, want multipart/alternative", mediaType)
+	}
+
+	parts := make(map[string]string)
+	mr := multipart.NewReader(msg.Body, params["boundary"])
+	for {
+		p, err := mr.NextPart()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			t.Fatalf("NextPart: %v", err)
+		}
+		// The reader decodes quoted-printable parts and drops the encoding header
+		content, err := io.ReadAll(p)
+		if err != nil {
+			t.Fatalf("read part: %v", err)
+		}
+		contentType, _, _ := mime.ParseMediaType(p.Header.Get("Content-Type"))
+		parts[contentType] = string(content)
+	}
+
+	plain, html := parts["text/plain"], parts["text/html"]
+	if !strings.Contains(plain, "payment failed: <timeout>") || !strings.Contains(plain, "order_id") {
+		t.Errorf("plain part misses the message or details:\n%s", plain)
+	}
+	if !strings.Contains(html, "payment failed: &lt;timeout&gt;") || !strings.Contains(html, "order_id") {
+		t.Errorf("HTML part misses the escaped message or details:\n%s", html)
+	}
+}
+
+func TestEmailNotifierRejectedRecipient(t *testing.T) {
+	host, port, sessions := startSMTPStub(t, "dev@example.com")
+
+	en, err := NewEmailNotifier(SMTPConfig{
+		Host:     host,
+		Port:     port,
+		From:     "sentinel@example.com",
+		Security: config.SMTPSecurityNone,
+	}, []string{"ops@example.com", "dev@example.com", "qa@example.com"}, "production")
+	if err != nil {
+		t.Fatalf("NewEmailNotifier: %v", err)
+	}
+
+	// Recipients delivered to by an earlier attempt are skipped
+	a := testAlert()
+	a.Delivered = []string{"qa@example.com"}
+
+	err = en.Notify(context.Background(), a)
+	var deliveryErr *DeliveryError
+	if !errors.As(err, &deliveryErr) {
+		t.Fatalf("Notify error = %v, want a *DeliveryError", err)
+	}
+	if _, ok := deliveryErr.Errors["dev@example.com"]; !ok || len(deliveryErr.Errors) != 1 {
+		t.Errorf("failed recipients = %v, want only dev@example.com", deliveryErr.Errors)
+	}
+	if !slices.Equal(deliveryErr.Delivered, []string{"ops@example.com"}) || !deliveryErr.Partial() {
+		t.Errorf("delivered = %v, want ops@example.com", deliveryErr.Delivered)
+	}
+
+	s := <-sessions
+	if s.auth != "" {
+		t.Errorf("AUTH PLAIN = %q, want no authentication without a username", s.auth)
+	}
+	if !slices.Equal(s.recipients, []string{"<ops@example.com>"}) {
+		t.Errorf("RCPT TO = %v, want only the accepted recipient", s.recipients)
+	}
+	if s.data == "" {
+		t.Errorf("message was not sent to the accepted recipient")
+	}
+}
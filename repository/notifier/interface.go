@@ -7,5 +7,10 @@ import (
 )
 
 type Notifier interface {
-	Notify(ctx context.Context, e entity.ErrorInfo) error
+	Notify(ctx context.Context, a entity.Alert) error
+}
+
+// DigestNotifier sends periodic digest reports. Digests are not templated.
+type DigestNotifier interface {
+	NotifyDigest(ctx context.Context, d entity.Digest) error
 }
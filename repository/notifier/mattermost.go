@@ -0,0 +1,133 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+type mattermostNotifier struct {
+	client      *http.Client
+	serverURL   string
+	token       string
+	channelIDs  []string
+	webhookURL  string
+	environment string
+	template    *msgTemplate
+}
+
+// NewMattermostNotifier returns a Notifier posting to Mattermost either with
+// a bot token to the given channels of the server or to an incoming webhook.
+func NewMattermostNotifier(serverURL, token string, channelIDs []string, webhookURL string, environment string) (*mattermostNotifier, error) {
+	if webhookURL == "" && (serverURL == "" || token == "" || len(channelIDs) == 0) {
+		return nil, fmt.Errorf("NewMattermostNotifier: either a server URL and bot token with channel IDs or a webhook URL is required")
+	}
+
+	return &mattermostNotifier{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		serverURL:   strings.TrimSuffix(serverURL, "/"),
+		token:       token,
+		channelIDs:  channelIDs,
+		webhookURL:  webhookURL,
+		environment: environment,
+	}, nil
+}
+
+type mattermostWebhookMessage struct {
+	Text string `json:"text"`
+}
+
+type mattermostPost struct {
+	ChannelID string `json:"channel_id"`
+	Message   string `json:"message"`
+}
+
+func (mn *mattermostNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	msg := buildMessage(mn.environment, a).truncated()
+	title, body, err := mn.template.render(mn.environment, a, mn.buildMsgTitle(msg), mn.buildMsgBody(msg))
+	if err != nil {
+		return fmt.Errorf("mattermostNotifier.Notify: %w", err)
+	}
+
+	err = mn.deliver(ctx, title+body, a.Delivered)
+	if err != nil {
+		return fmt.Errorf("mattermostNotifier.Notify: %w", err)
+	}
+
+	return nil
+}
+
+func (mn *mattermostNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	msg := buildDigestMessage(mn.environment, d).truncated()
+
+	err := mn.deliver(ctx, mn.buildMsgTitle(msg)+mn.buildMsgBody(msg), nil)
+	if err != nil {
+		return fmt.Errorf("mattermostNotifier.NotifyDigest: %w", err)
+	}
+
+	return nil
+}
+
+// deliver posts the text to the webhook or to all channels not yet delivered to.
+func (mn *mattermostNotifier) deliver(ctx context.Context, text string, delivered []string) error {
+	if mn.webhookURL != "" {
+		_, err := postJSON(ctx, mn.client, mn.webhookURL, nil, mattermostWebhookMessage{Text: text})
+		if err != nil {
+			return fmt.Errorf("mattermostNotifier.deliver: %w", err)
+		}
+		return nil
+	}
+
+	headers := map[string]string{"Authorization": "Bearer " + mn.token}
+	err := deliverEach(mn.channelIDs, delivered, func(i int) error {
+		post := mattermostPost{ChannelID: mn.channelIDs[i], Message: text}
+		_, err := postJSON(ctx, mn.client, mn.serverURL+"/api/v4/posts", headers, post)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("mattermostNotifier.deliver: %w", err)
+	}
+
+	return nil
+}
+
+func (mn *mattermostNotifier) WithTemplate(title, body string) (Notifier, error) {
+	t, err := newTemplate(title, body, templateFormat{escape: escapeMarkdown})
+	if err != nil {
+		return nil, fmt.Errorf("mattermostNotifier.WithTemplate: %w", err)
+	}
+
+	c := *mn
+	c.template = t
+	return &c, nil
+}
+
+func (mn *mattermostNotifier) buildMsgTitle(msg message) string {
+	return fmt.Sprintf("#### %s\n", escapeMarkdown(msg.Title))
+}
+
+func (mn *mattermostNotifier) buildMsgBody(msg message) string {
+	var buffer bytes.Buffer
+
+	// Main error information
+	for _, f := range msg.Fields {
+		buffer.WriteString(fmt.Sprintf("**%s %s:**%s\n", f.Emoji, f.Label, f.text(escapeMarkdown)))
+	}
+
+	// Separator for Details section
+	if len(msg.Details) > 0 {
+		buffer.WriteString("\n**📋 _Additional details_**\n")
+	}
+
+	// Details section
+	for _, d := range msg.Details {
+		buffer.WriteString(fmt.Sprintf("_%s_:\n```\n%s\n```\n", escapeMarkdown(d.Key), escapeCodeBlock(d.Value)))
+	}
+
+	return buffer.String()
+}
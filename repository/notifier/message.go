@@ -0,0 +1,159 @@
+package notifier
+
+import (
+	"maps"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+// message is the provider independent content of an alert.
+// Providers render it in their own markup.
+type message struct {
+	Title   string
+	Fields  []field
+	Details []detail // Sorted by key, without empty values
+}
+
+type field struct {
+	Emoji string
+	Label string
+	Value string
+	Lines []string // Shown below the label instead of the value, one per line
+}
+
+// text returns the value of the field escaped with escape, preceded by the
+// separator from the label.
+func (f field) text(escape func(string) string) string {
+	if len(f.Lines) == 0 {
+		return " " + escape(f.Value)
+	}
+
+	lines := make([]string, len(f.Lines))
+	for i, l := range f.Lines {
+		lines[i] = escape(l)
+	}
+	return "\n" + strings.Join(lines, "\n")
+}
+
+type detail struct {
+	Key   string
+	Value string
+}
+
+func buildMessage(environment string, a entity.Alert) message {
+	e := a.Error
+
+	title := "❗ Error from Sentinel"
+	switch a.Kind {
+	case entity.AlertKindRegression:
+		title = "🔁 Regression from Sentinel: a resolved issue is back"
+	case entity.AlertKindCondition:
+		title = "📈 Alert condition met in Sentinel"
+	case entity.AlertKindSpike:
+		title = "📊 Error spike from Sentinel"
+	}
+
+	fields := []field{
+		{Emoji: "🔍", Label: "Environment", Value: environment},
+		{Emoji: "🛠️", Label: "Service", Value: e.Service},
+		{Emoji: "🔄", Label: "Operation", Value: e.Operation},
+		{Emoji: "🏷️", Label: "Code", Value: e.Code},
+		{Emoji: "💬", Label: "Message", Value: e.Message},
+	}
+	if a.Reason != "" {
+		fields = append(fields, field{Emoji: "📈", Label: "Reason", Value: a.Reason})
+	}
+
+	return message{
+		Title:   title,
+		Fields:  fields,
+		Details: buildDetails(e.Details),
+	}
+}
+
+// buildDetails returns the non-empty details sorted by key, so that messages
+// render them in a stable order.
+func buildDetails(details map[string]string) []detail {
+	out := make([]detail, 0, len(details))
+	for _, k := range slices.Sorted(maps.Keys(details)) {
+		if details[k] != "" {
+			out = append(out, detail{Key: k, Value: details[k]})
+		}
+	}
+	return out
+}
+
+// truncated returns a copy of the message with values cut to inlineValueLimit runes.
+func (m message) truncated() message {
+	fields := make([]field, len(m.Fields))
+	for i, f := range m.Fields {
+		f.Value = truncateRunes(inlineValueLimit, f.Value)
+		fields[i] = f
+	}
+	m.Fields = fields
+
+	details := make([]detail, len(m.Details))
+	for i, d := range m.Details {
+		d.Value = truncateRunes(inlineValueLimit, d.Value)
+		details[i] = d
+	}
+	m.Details = details
+
+	return m
+}
+
+const (
+	inlineValueLimit    = 1000 // Longer values are attached as files
+	inlineSummaryLength = 300  // Length of the inline summary of attached values
+)
+
+// attachment is a value too large to be shown inline, sent as a file.
+type attachment struct {
+	Name    string
+	Content []byte
+}
+
+// withAttachments moves field and detail values longer than inlineValueLimit
+// to attachments, leaving a short summary of them inline.
+func (m message) withAttachments() (message, []attachment) {
+	var attachments []attachment
+
+	summarize := func(name, v string) string {
+		if utf8.RuneCountInString(v) <= inlineValueLimit {
+			return v
+		}
+		attachments = append(attachments, attachment{Name: fileName(name), Content: []byte(v)})
+		return truncateRunes(inlineSummaryLength, v)
+	}
+
+	fields := make([]field, len(m.Fields))
+	for i, f := range m.Fields {
+		f.Value = summarize(f.Label, f.Value)
+		fields[i] = f
+	}
+	m.Fields = fields
+
+	details := make([]detail, len(m.Details))
+	for i, d := range m.Details {
+		d.Value = summarize(d.Key, d.Value)
+		details[i] = d
+	}
+	m.Details = details
+
+	return m, attachments
+}
+
+// fileName turns a field label or detail key into a safe attachment file name.
+func fileName(name string) string {
+	name = strings.Map(func(r rune) rune {
+		if r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r) || r == '-' || r == '_' || r == '.') {
+			return r
+		}
+		return '_'
+	}, strings.ToLower(name))
+	return name + ".txt"
+}
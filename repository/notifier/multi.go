@@ -0,0 +1,164 @@
+package notifier
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+// Target is a notifier known by name, so that its failures can be told apart.
+type Target struct {
+	Name     string
+	Notifier Notifier
+}
+
+// DigestTarget is a digest notifier known by name.
+type DigestTarget struct {
+	Name     string
+	Notifier DigestNotifier
+}
+
+// DeliveryError reports the targets a notification failed to be delivered to.
+// Notifiers sending to several recipients report them the same way, by key.
+type DeliveryError struct {
+	Errors    map[string]error // Failures by target name
+	Total     int              // Number of targets the notification was sent to
+	Delivered []string         // Names of the targets the notification was delivered to
+}
+
+func (e *DeliveryError) Error() string {
+	names := make([]string, 0, len(e.Errors))
+	for name := range e.Errors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	msgs := make([]string, 0, len(names))
+	for _, name := range names {
+		msgs = append(msgs, fmt.Sprintf("%s: %v", name, e.Errors[name]))
+	}
+	return fmt.Sprintf("delivery failed for %d of %d targets: %s", len(e.Errors), e.Total, strings.Join(msgs, "; "))
+}
+
+func (e *DeliveryError) Unwrap() []error {
+	errs := make([]error, 0, len(e.Errors))
+	for _, err := range e.Errors {
+		errs = append(errs, err)
+	}
+	return errs
+}
+
+// Partial reports whether the notification reached at least one target.
+func (e *DeliveryError) Partial() bool {
+	return len(e.Errors) < e.Total
+}
+
+// deliverEach sends to the recipients with the given keys one by one, by
+// their index, skipping those in delivered. It returns a *DeliveryError by
+// recipient key if any failed, so that retries reach only the failed ones.
+func deliverEach(keys []string, delivered []string, send func(i int) error) error {
+	var (
+		errs  = make(map[string]error)
+		sent  []string
+		total int
+	)
+
+	for i, key := range keys {
+		if slices.Contains(delivered, key) {
+			continue
+		}
+		total++
+
+		err := send(i)
+		if err != nil {
+			errs[key] = err
+		} else {
+			sent = append(sent, key)
+		}
+	}
+
+	if len(errs) > 0 {
+		return &DeliveryError{Errors: errs, Total: total, Delivered: sent}
+	}
+	return nil
+}
+
+// recipientsOf returns the recipients of the target in the delivered list,
+// where they are kept as "<target>/<recipient>".
+func recipientsOf(target string, delivered []string) []string {
+	var out []string
+	for _, d := range delivered {
+		if r, ok := strings.CutPrefix(d, target+"/"); ok {
+			out = append(out, r)
+		}
+	}
+	return out
+}
+
+type multiNotifier struct {
+	targets []Target
+}
+
+// NewMultiNotifier returns a Notifier fanning out to all targets concurrently.
+func NewMultiNotifier(targets ...Target) *multiNotifier {
+	return &multiNotifier{targets: targets}
+}
+
+// Notify skips the targets in the Delivered list of the alert. It returns a
+// *DeliveryError if any of the other targets failed. Use its Partial method
+// to tell whether the notification was delivered anywhere. Recipients reached
+// by failed targets are listed as "<target>/<recipient>" in its Delivered
+// list, and skipped by the targets when passed back in the alert.
+func (mn *multiNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	var (
+		mu        sync.Mutex
+		wg        sync.WaitGroup
+		errs      = make(map[string]error)
+		delivered []string
+		total     int
+	)
+
+	for _, t := range mn.targets {
+		if slices.Contains(a.Delivered, t.Name) {
+			continue
+		}
+		total++
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+
+			ta := a
+			ta.Delivered = recipientsOf(t.Name, a.Delivered)
+			err := t.Notifier.Notify(ctx, ta)
+
+			mu.Lock()
+			defer mu.Unlock()
+			if err == nil {
+				delivered = append(delivered, t.Name)
+				return
+			}
+
+			errs[t.Name] = err
+			var recipientsErr *DeliveryError
+			if errors.As(err, &recipientsErr) {
+				for _, r := range recipientsErr.Delivered {
+					delivered = append(delivered, t.Name+"/"+r)
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	if len(errs) > 0 {
+		return fmt.Errorf("multiNotifier.Notify: %w", &DeliveryError{Errors: errs, Total: total, Delivered: delivered})
+	}
+
+	return nil
+}
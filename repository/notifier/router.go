@@ -0,0 +1,135 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+// Matcher reports whether a value matches a pattern.
+type Matcher func(value string) bool
+
+// CompilePattern compiles a glob ("*" and "?" wildcards) or, when prefixed
+// with "re:", a regular expression into a Matcher. The empty pattern matches anything.
+func CompilePattern(pattern string) (Matcher, error) {
+	if pattern == "" {
+		return func(string) bool { return true }, nil
+	}
+
+	expr, isRegexp := strings.CutPrefix(pattern, "re:")
+	if !isRegexp {
+		expr = "^" + strings.NewReplacer(`\*`, ".*", `\?`, ".").Replace(regexp.QuoteMeta(pattern)) + "$"
+	}
+
+	re, err := regexp.Compile(expr)
+	if err != nil {
+		return nil, fmt.Errorf("CompilePattern: %q: %w", pattern, err)
+	}
+	return re.MatchString, nil
+}
+
+// Match holds the matchers an error is matched against.
+// A nil matcher matches anything.
+type Match struct {
+	Service     Matcher
+	Operation   Matcher
+	Code        Matcher
+	Environment Matcher
+	Details     map[string]Matcher // Matchers by detail key, the detail must be present
+}
+
+// Matches reports whether the error, reported in environment, matches all matchers.
+func (m Match) Matches(environment string, e entity.ErrorInfo) bool {
+	for _, mv := range []struct {
+		matcher Matcher
+		value   string
+	}{
+		{m.Service, e.Service},
+		{m.Operation, e.Operation},
+		{m.Code, e.Code},
+		{m.Environment, environment},
+	} {
+		if mv.matcher != nil && !mv.matcher(mv.value) {
+			return false
+		}
+	}
+
+	for key, matcher := range m.Details {
+		value, ok := e.Details[key]
+		if !ok || !matcher(value) {
+			return false
+		}
+	}
+
+	return true
+}
+
+// Route sends alerts matching all of its matchers to its targets.
+type Route struct {
+	Name  string
+	Match Match
+
+	Targets []Target
+	// Continue makes the router evaluate the following routes after this one matched
+	Continue bool
+}
+
+type router struct {
+	environment string
+	routes      []Route
+	defaults    []Target
+}
+
+// NewRouter returns a Notifier choosing the targets of an alert by routes.
+// Routes are evaluated in order and the first matching route wins, unless it
+// has Continue set. Alerts matching no route go to the default targets.
+func NewRouter(environment string, routes []Route, defaults []Target) *router {
+	return &router{
+		environment: environment,
+		routes:      routes,
+		defaults:    defaults,
+	}
+}
+
+func (r *router) Notify(ctx context.Context, a entity.Alert) error {
+	err := NewMultiNotifier(r.targets(a.Error)...).Notify(ctx, a)
+	if err != nil {
+		return fmt.Errorf("router.Notify: %w", err)
+	}
+	return nil
+}
+
+// targets returns the deduplicated targets of the routes matching the error.
+func (r *router) targets(e entity.ErrorInfo) []Target {
+	var (
+		targets []Target
+		seen    = make(map[string]bool)
+		matched bool
+	)
+
+	for _, route := range r.routes {
+		if !route.Match.Matches(r.environment, e) {
+			continue
+		}
+
+		matched = true
+		for _, t := range route.Targets {
+			if !seen[t.Name] {
+				seen[t.Name] = true
+				targets = append(targets, t)
+			}
+		}
+
+		if !route.Continue {
+			break
+		}
+	}
+
+	if !matched {
+		return r.defaults
+	}
+	return targets
+}
@@ -0,0 +1,218 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+const slackPostMessageURL = "https://slack.com/api/chat.postMessage"
+
+// Limits of Slack Block Kit, longer messages are rejected with invalid_blocks
+const (
+	slackHeaderLimit  = 150
+	slackSectionLimit = 3000
+	slackBlockLimit   = 50
+)
+
+type slackNotifier struct {
+	client      *http.Client
+	token       string
+	channelIDs  []string
+	webhookURL  string
+	environment string
+	template    *msgTemplate
+}
+
+// NewSlackNotifier returns a Notifier posting to Slack either with a bot token
+// to the given channels or to an incoming webhook.
+func NewSlackNotifier(token string, channelIDs []string, webhookURL string, environment string) (*slackNotifier, error) {
+	if webhookURL == "" && (token == "" || len(channelIDs) == 0) {
+		return nil, fmt.Errorf("NewSlackNotifier: either a bot token with channel IDs or a webhook URL is required")
+	}
+
+	return &slackNotifier{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		token:       token,
+		channelIDs:  channelIDs,
+		webhookURL:  webhookURL,
+		environment: environment,
+	}, nil
+}
+
+type slackMessage struct {
+	Channel string       `json:"channel,omitempty"`
+	Text    string       `json:"text"` // Fallback for notifications
+	Blocks  []slackBlock `json:"blocks"`
+}
+
+type slackBlock struct {
+	Type string     `json:"type"`
+	Text *slackText `json:"text,omitempty"`
+}
+
+type slackText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type slackResponse struct {
+	OK    bool   `json:"ok"`
+	Error string `json:"error"`
+}
+
+func (sn *slackNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	msg := sn.buildMsg(buildMessage(sn.environment, a).truncated())
+
+	// The user defined template replaces the header and the sections below it
+	title, body, err := sn.template.render(sn.environment, a, "", "")
+	if err != nil {
+		return fmt.Errorf("slackNotifier.Notify: %w", err)
+	}
+	if title != "" {
+		msg.Text = title
+		msg.Blocks[0] = slackHeader(title)
+	}
+	if body != "" {
+		msg.Blocks = append(msg.Blocks[:1], slackSection(body))
+	}
+
+	err = sn.deliver(ctx, msg, a.Delivered)
+	if err != nil {
+		return fmt.Errorf("slackNotifier.Notify: %w", err)
+	}
+
+	return nil
+}
+
+func (sn *slackNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	err := sn.deliver(ctx, sn.buildMsg(buildDigestMessage(sn.environment, d).truncated()), nil)
+	if err != nil {
+		return fmt.Errorf("slackNotifier.NotifyDigest: %w", err)
+	}
+
+	return nil
+}
+
+// deliver posts the message to the webhook or to all channels not yet delivered to.
+func (sn *slackNotifier) deliver(ctx context.Context, msg slackMessage, delivered []string) error {
+	if sn.webhookURL != "" {
+		err := sn.post(ctx, sn.webhookURL, msg)
+		if err != nil {
+			return fmt.Errorf("slackNotifier.deliver: %w", err)
+		}
+		return nil
+	}
+
+	err := deliverEach(sn.channelIDs, delivered, func(i int) error {
+		msg.Channel = sn.channelIDs[i]
+		return sn.post(ctx, slackPostMessageURL, msg)
+	})
+	if err != nil {
+		return fmt.Errorf("slackNotifier.deliver: %w", err)
+	}
+
+	return nil
+}
+
+func (sn *slackNotifier) WithTemplate(title, body string) (Notifier, error) {
+	t, err := newTemplate(title, body, templateFormat{escape: escapeSlack})
+	if err != nil {
+		return nil, fmt.Errorf("slackNotifier.WithTemplate: %w", err)
+	}
+
+	c := *sn
+	c.template = t
+	return &c, nil
+}
+
+func (sn *slackNotifier) buildMsg(msg message) slackMessage {
+	var buffer bytes.Buffer
+
+	// Main error information
+	for _, f := range msg.Fields {
+		buffer.WriteString(fmt.Sprintf("*%s %s:*%s\n", f.Emoji, f.Label, f.text(escapeSlack)))
+	}
+
+	blocks := []slackBlock{slackHeader(msg.Title), slackSection(buffer.String())}
+
+	// Details section, one block per detail as each holds up to inlineValueLimit runes
+	if len(msg.Details) > 0 {
+		blocks = append(blocks, slackBlock{Type: "divider"}, slackSection("*📋 _Additional details_*"))
+	}
+	for i, d := range msg.Details {
+		if len(blocks) == slackBlockLimit-1 && i < len(msg.Details)-1 {
+			blocks = append(blocks, slackSection(fmt.Sprintf("_%d more details_", len(msg.Details)-i)))
+			break
+		}
+		blocks = append(blocks, slackSection(fmt.Sprintf("_%s_: ```%s```", escapeSlack(d.Key), escapeCodeBlock(escapeSlack(d.Value)))))
+	}
+
+	return slackMessage{
+		Text:   msg.Title,
+		Blocks: blocks,
+	}
+}
+
+// slackHeader returns a header block of the title, cut to the header limit.
+func slackHeader(title string) slackBlock {
+	return slackBlock{Type: "header", Text: &slackText{Type: "plain_text", Text: truncateText(title, slackHeaderLimit, nil)}}
+}
+
+// slackSection returns a mrkdwn section block of the text, cut to the section
+// limit with a code block cut in half closed.
+func slackSection(text string) slackBlock {
+	if textLength(text) > slackSectionLimit {
+		text = truncateText(text, slackSectionLimit-len("```"), markdownMarkup)
+		closing, _ := markdownMarkup.unclosed(text)
+		text += closing
+	}
+	return slackBlock{Type: "section", Text: &slackText{Type: "mrkdwn", Text: text}}
+}
+
+func (sn *slackNotifier) post(ctx context.Context, url string, msg slackMessage) error {
+	body, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("slackNotifier.post: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("slackNotifier.post: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json; charset=utf-8")
+	if url == slackPostMessageURL {
+		req.Header.Set("Authorization", "Bearer "+sn.token)
+	}
+
+	resp, err := sn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("slackNotifier.post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode != http.StatusOK {
+		return fmt.Errorf("slackNotifier.post: unexpected status %s: %s", resp.Status, respBody)
+	}
+
+	// Incoming webhooks reply with plain "ok", the Web API with a JSON object
+	if url == slackPostMessageURL {
+		var out slackResponse
+		err = json.Unmarshal(respBody, &out)
+		if err != nil {
+			return fmt.Errorf("slackNotifier.post: %w", err)
+		}
+		if !out.OK {
+			return fmt.Errorf("slackNotifier.post: %s", out.Error)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,169 @@
+package notifier
+
+import (
+	"regexp"
+	"strings"
+	"unicode/utf16"
+)
+
+const (
+	telegramMessageLimit = 4096
+	discordMessageLimit  = 2000
+)
+
+// textLength returns the length of s in UTF-16 code units, the unit message
+// length limits of Telegram and Discord are measured in.
+func textLength(s string) int {
+	n := 0
+	for _, r := range s {
+		n += utf16.RuneLen(r)
+	}
+	return n
+}
+
+// markupReserve is the room kept in split messages for the markup closing
+// and reopening the elements open across the split.
+const markupReserve = 256
+
+// markup keeps messages split in the middle of an element valid, by closing
+// it at the end of a message and opening it again in the next one.
+type markup struct {
+	// unclosed returns the markup closing the elements left open at the end
+	// of text and the markup opening them again.
+	unclosed func(text string) (closing, reopening string)
+	// trimCut drops a tag or entity cut in half at the end of s.
+	trimCut func(s string) string
+}
+
+var htmlTagPattern = regexp.MustCompile(`<(/?)([a-zA-Z][a-zA-Z0-9-]*)[^<>]*>`)
+
+// htmlMarkup is the HTML subset of Telegram messages.
+var htmlMarkup = &markup{
+	unclosed: func(text string) (string, string) {
+		type tag struct{ name, open string }
+		var open []tag
+		for _, m := range htmlTagPattern.FindAllStringSubmatch(text, -1) {
+			name := strings.ToLower(m[2])
+			if m[1] == "" {
+				open = append(open, tag{name: name, open: m[0]})
+				continue
+			}
+			for i := len(open) - 1; i >= 0; i-- {
+				if open[i].name == name {
+					open = open[:i]
+					break
+				}
+			}
+		}
+
+		var closing, reopening strings.Builder
+		for i := len(open) - 1; i >= 0; i-- {
+			closing.WriteString("</" + open[i].name + ">")
+		}
+		for _, t := range open {
+			reopening.WriteString(t.open)
+		}
+		return closing.String(), reopening.String()
+	},
+	trimCut: func(s string) string {
+		if i := strings.LastIndexByte(s, '<'); i > strings.LastIndexByte(s, '>') {
+			s = s[:i]
+		}
+		if i := strings.LastIndexByte(s, '&'); i > strings.LastIndexByte(s, ';') {
+			s = s[:i]
+		}
+		return s
+	},
+}
+
+// markdownMarkup is the Markdown of Discord messages, where code blocks are
+// the elements spanning lines.
+var markdownMarkup = &markup{
+	unclosed: func(text string) (string, string) {
+		if strings.Count(text, "```")%2 == 0 {
+			return "", ""
+		}
+		// A line break after the fence keeps the text from being taken for its language
+		return "```", "```\n"
+	},
+	trimCut: func(s string) string {
+		return strings.TrimRight(s, "`")
+	},
+}
+
+// truncateText shortens s to at most limit UTF-16 code units, marking cut
+// strings with "...". Markup cut in half at the end is dropped if m is set.
+func truncateText(s string, limit int, m *markup) string {
+	if textLength(s) <= limit {
+		return s
+	}
+
+	n := 0
+	for i, r := range s {
+		n += utf16.RuneLen(r)
+		if n > limit-3 {
+			if m != nil {
+				return m.trimCut(s[:i]) + "..."
+			}
+			return s[:i] + "..."
+		}
+	}
+	return s
+}
+
+// splitMessage packs the blocks into as few messages of at most limit UTF-16
+// code units as possible. Blocks are kept whole if they fit, longer blocks are
+// split at line breaks and overlong lines are cut. With markup m, elements
+// open where a message ends are closed in it and opened again in the next one.
+func splitMessage(blocks []string, limit int, m *markup) []string {
+	var (
+		texts   []string
+		current strings.Builder
+		length  int
+	)
+
+	unclosed := func(text string) (string, string) {
+		if m == nil {
+			return "", ""
+		}
+		return m.unclosed(text)
+	}
+
+	flush := func() {
+		text := current.String()
+		closing, reopening := unclosed(text)
+		if strings.TrimSpace(text) != strings.TrimSpace(reopening) {
+			texts = append(texts, text+closing)
+		}
+		current.Reset()
+		current.WriteString(reopening)
+		length = textLength(reopening)
+	}
+	add := func(s string) {
+		n := textLength(s)
+		closing, _ := unclosed(current.String() + s)
+		if length+n+textLength(closing) > limit {
+			flush()
+		}
+		current.WriteString(s)
+		length += n
+	}
+
+	lineLimit := limit
+	if m != nil {
+		lineLimit -= markupReserve
+	}
+
+	for _, block := range blocks {
+		if textLength(block) <= lineLimit {
+			add(block)
+			continue
+		}
+		for _, line := range strings.SplitAfter(block, "\n") {
+			add(truncateText(line, lineLimit, m))
+		}
+	}
+	flush()
+
+	return texts
+}
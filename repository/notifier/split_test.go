@@ -0,0 +1,114 @@
+package notifier
+
+import (
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+)
+
+func TestSplitMessage(t *testing.T) {
+	lines := func(n int) string {
+		return strings.Repeat("line 0123456789\n", n)
+	}
+
+	tests := []struct {
+		name   string
+		blocks []string
+		limit  int
+		markup *markup
+		want   []string
+	}{
+		{
+			name:   "blocks packed under the limit",
+			blocks: []string{"aaaaa\n", "bbbbb\n", "ccccc\n"},
+			limit:  13,
+			want:   []string{"aaaaa\nbbbbb\n", "ccccc\n"},
+		},
+		{
+			name:   "long block split at line breaks",
+			blocks: []string{"aaaa\nbbbb\ncccc\n"},
+			limit:  10,
+			want:   []string{"aaaa\nbbbb\n", "cccc\n"},
+		},
+		{
+			name:   "single line longer than the limit",
+			blocks: []string{strings.Repeat("x", 30)},
+			limit:  10,
+			want:   []string{"xxxxxxx..."},
+		},
+		{
+			name:   "multi-byte runes at the boundary",
+			blocks: []string{strings.Repeat("😀", 20)}, // Two UTF-16 code units each
+			limit:  14,
+			want:   []string{"😀😀😀😀😀..."},
+		},
+		{
+			name:   "HTML tags closed and reopened",
+			blocks: []string{"<b>Stack</b>\n<pre>" + lines(30) + "</pre>\n"},
+			limit:  300,
+			markup: htmlMarkup,
+			want:   []string{"<b>Stack</b>\n<pre>" + lines(17) + "</pre>", "<pre>" + lines(13) + "</pre>\n"},
+		},
+		{
+			name:   "HTML entity not cut in half",
+			blocks: []string{"<code>" + strings.Repeat("&amp;", 100) + "</code>"},
+			limit:  300,
+			markup: htmlMarkup,
+			want:   []string{"<code>" + strings.Repeat("&amp;", 7) + "...</code>"},
+		},
+		{
+			name:   "code fences closed and reopened",
+			blocks: []string{"**Stack**\n```\n" + lines(30) + "```\n"},
+			limit:  300,
+			markup: markdownMarkup,
+			want:   []string{"**Stack**\n```\n" + lines(17) + "```", "```\n" + lines(13) + "```\n"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := splitMessage(tt.blocks, tt.limit, tt.markup)
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("splitMessage =\n%q\nwant\n%q", got, tt.want)
+			}
+
+			for i, part := range got {
+				if n := textLength(part); n > tt.limit {
+					t.Errorf("part %d is %d code units long, over the limit of %d", i, n, tt.limit)
+				}
+				if !utf8.ValidString(part) {
+					t.Errorf("part %d is not valid UTF-8: %q", i, part)
+				}
+				if tt.markup != nil {
+					if closing, _ := tt.markup.unclosed(part); closing != "" {
+						t.Errorf("part %d leaves elements open: %q", i, part)
+					}
+				}
+			}
+		})
+	}
+}
+
+func TestEscapeCodeBlock(t *testing.T) {
+	tests := []struct {
+		in   string
+		want string
+	}{
+		{"plain", "plain"},
+		{"a`b", "a`b"},
+		{"a``b", "a`\u200b`b"},
+		{"a```b", "a`\u200b`\u200b`b"},
+		{"`quoted`", "\u200b`quoted`\u200b"},
+	}
+
+	for _, tt := range tests {
+		got := escapeCodeBlock(tt.in)
+		if got != tt.want {
+			t.Errorf("escapeCodeBlock(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+		if strings.Contains(got, "``") {
+			t.Errorf("escapeCodeBlock(%q) = %q still contains a fence", tt.in, got)
+		}
+	}
+}
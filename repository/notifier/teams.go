@@ -0,0 +1,162 @@
+package notifier
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+type teamsNotifier struct {
+	client      *http.Client
+	webhookURLs []string
+	environment string
+	template    *msgTemplate
+}
+
+// NewTeamsNotifier returns a Notifier posting Adaptive Cards to Microsoft Teams
+// incoming webhooks (or Workflows webhooks accepting the same payload).
+func NewTeamsNotifier(webhookURLs []string, environment string) (*teamsNotifier, error) {
+	if len(webhookURLs) == 0 {
+		return nil, fmt.Errorf("NewTeamsNotifier: at least one webhook URL is required")
+	}
+
+	return &teamsNotifier{
+		client:      &http.Client{Timeout: 10 * time.Second},
+		webhookURLs: webhookURLs,
+		environment: environment,
+	}, nil
+}
+
+type teamsMessage struct {
+	Type        string            `json:"type"`
+	Attachments []teamsAttachment `json:"attachments"`
+}
+
+type teamsAttachment struct {
+	ContentType string    `json:"contentType"`
+	Content     teamsCard `json:"content"`
+}
+
+type teamsCard struct {
+	Schema  string         `json:"$schema"`
+	Type    string         `json:"type"`
+	Version string         `json:"version"`
+	Body    []teamsElement `json:"body"`
+}
+
+type teamsElement struct {
+	Type     string      `json:"type"`
+	Text     string      `json:"text,omitempty"`
+	Weight   string      `json:"weight,omitempty"`
+	Size     string      `json:"size,omitempty"`
+	FontType string      `json:"fontType,omitempty"`
+	Wrap     bool        `json:"wrap,omitempty"`
+	Facts    []teamsFact `json:"facts,omitempty"`
+}
+
+type teamsFact struct {
+	Title string `json:"title"`
+	Value string `json:"value"`
+}
+
+func (tn *teamsNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	msg := tn.buildMsg(buildMessage(tn.environment, a).truncated())
+
+	// The user defined template replaces the title and the elements below it
+	title, body, err := tn.template.render(tn.environment, a, "", "")
+	if err != nil {
+		return fmt.Errorf("teamsNotifier.Notify: %w", err)
+	}
+	card := &msg.Attachments[0].Content
+	if title != "" {
+		card.Body[0].Text = title
+	}
+	if body != "" {
+		card.Body = append(card.Body[:1], teamsElement{Type: "TextBlock", Text: body, Wrap: true})
+	}
+
+	err = tn.deliver(ctx, msg, a.Delivered)
+	if err != nil {
+		return fmt.Errorf("teamsNotifier.Notify: %w", err)
+	}
+
+	return nil
+}
+
+func (tn *teamsNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	err := tn.deliver(ctx, tn.buildMsg(buildDigestMessage(tn.environment, d).truncated()), nil)
+	if err != nil {
+		return fmt.Errorf("teamsNotifier.NotifyDigest: %w", err)
+	}
+
+	return nil
+}
+
+// deliver posts the message to all webhooks not yet delivered to. Webhooks
+// are told apart by their position, their URLs hold secrets.
+func (tn *teamsNotifier) deliver(ctx context.Context, msg teamsMessage, delivered []string) error {
+	err := deliverEach(webhookKeys(len(tn.webhookURLs)), delivered, func(i int) error {
+		_, err := postJSON(ctx, tn.client, tn.webhookURLs[i], nil, msg)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("teamsNotifier.deliver: %w", err)
+	}
+
+	return nil
+}
+
+func (tn *teamsNotifier) WithTemplate(title, body string) (Notifier, error) {
+	t, err := newTemplate(title, body, templateFormat{escape: escapeMarkdown})
+	if err != nil {
+		return nil, fmt.Errorf("teamsNotifier.WithTemplate: %w", err)
+	}
+
+	c := *tn
+	c.template = t
+	return &c, nil
+}
+
+func (tn *teamsNotifier) buildMsg(msg message) teamsMessage {
+	// Main error information
+	facts := make([]teamsFact, 0, len(msg.Fields))
+	for _, f := range msg.Fields {
+		facts = append(facts, teamsFact{
+			Title: fmt.Sprintf("%s %s", f.Emoji, f.Label),
+			Value: strings.TrimLeft(f.text(escapeMarkdown), " \n"),
+		})
+	}
+
+	body := []teamsElement{
+		{Type: "TextBlock", Text: escapeMarkdown(msg.Title), Weight: "Bolder", Size: "Medium", Wrap: true},
+		{Type: "FactSet", Facts: facts},
+	}
+	if len(msg.Details) > 0 {
+		body = append(body, teamsElement{Type: "TextBlock", Text: "📋 _Additional details_", Weight: "Bolder", Wrap: true})
+	}
+
+	// Details section
+	for _, d := range msg.Details {
+		body = append(body,
+			teamsElement{Type: "TextBlock", Text: fmt.Sprintf("_%s_:", escapeMarkdown(d.Key)), Wrap: true},
+			teamsElement{Type: "TextBlock", Text: escapeMarkdownText(d.Value), FontType: "Monospace", Wrap: true},
+		)
+	}
+
+	return teamsMessage{
+		Type: "message",
+		Attachments: []teamsAttachment{{
+			ContentType: "application/vnd.microsoft.card.adaptive",
+			Content: teamsCard{
+				Schema:  "http://adaptivecards.io/schemas/adaptive-card.json",
+				Type:    "AdaptiveCard",
+				Version: "1.4",
+				Body:    body,
+			},
+		}},
+	}
+}
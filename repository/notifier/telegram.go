@@ -4,42 +4,48 @@ import (
 	"bytes"
 	"context"
 	"fmt"
+	"strconv"
+	"strings"
 
 	"github.com/code19m/sentinel/entity"
-	"github.com/nikoksr/notify"
-	"github.com/nikoksr/notify/service/telegram"
+	tgbotapi "github.com/go-telegram-bot-api/telegram-bot-api"
 )
 
 type telegramNotifier struct {
-	notifier    notify.Notifier
+	client      *tgbotapi.BotAPI
+	chatIDs     []int64
 	environment string
+	template    *msgTemplate
 }
 
 func NewTelegramNotifier(token string, chatIDs []int64, environment string) (*telegramNotifier, error) {
-	tg, err := telegram.New(token)
+	client, err := tgbotapi.NewBotAPI(token)
 	if err != nil {
 		return nil, fmt.Errorf("NewTelegramNotifier: %w", err)
 	}
-	tg.AddReceivers(chatIDs...)
-
-	n := notify.New()
-	n.UseServices(tg)
 
 	return &telegramNotifier{
-		notifier:    n,
+		client:      client,
+		chatIDs:     chatIDs,
 		environment: environment,
 	}, nil
 }
 
-func (tn *telegramNotifier) Notify(ctx context.Context, e entity.ErrorInfo) error {
-	// Build the message title
-	msgTitle := tn.buildMsgTitle()
+func (tn *telegramNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	// Values too large for a message are sent as documents
+	msg, attachments := buildMessage(tn.environment, a).withAttachments()
 
-	// Build the message body
-	msgBody := tn.buildMsgBody(e)
+	// Build the message title and body, from the user defined template if any
+	msgBody := tn.buildMsgBody(msg, attachments)
+	msgTitle, body, err := tn.template.render(tn.environment, a, tn.buildMsgTitle(msg), strings.Join(msgBody, ""))
+	if err != nil {
+		return fmt.Errorf("telegramNotifier.Notify: %w", err)
+	}
+	if tn.template != nil {
+		msgBody = []string{body}
+	}
 
-	// Send the message
-	err := tn.notifier.Send(ctx, msgTitle, msgBody)
+	err = tn.deliver(ctx, msgTitle, msgBody, attachments, a.Delivered)
 	if err != nil {
 		return fmt.Errorf("telegramNotifier.Notify: %w", err)
 	}
@@ -47,32 +53,106 @@ func (tn *telegramNotifier) Notify(ctx context.Context, e entity.ErrorInfo) erro
 	return nil
 }
 
-func (tn *telegramNotifier) buildMsgTitle() string {
-	return "<b>❗ Error from Sentinel</b>\n"
+func (tn *telegramNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	msg, attachments := buildDigestMessage(tn.environment, d).withAttachments()
+
+	err := tn.deliver(ctx, tn.buildMsgTitle(msg), tn.buildMsgBody(msg, attachments), attachments, nil)
+	if err != nil {
+		return fmt.Errorf("telegramNotifier.NotifyDigest: %w", err)
+	}
+
+	return nil
+}
+
+// deliver sends the title and body blocks, split to fit the message length
+// limit, and the attachments to all chats not yet delivered to.
+func (tn *telegramNotifier) deliver(ctx context.Context, title string, body []string, attachments []attachment, delivered []string) error {
+	texts := splitMessage(append([]string{title + "\n"}, body...), telegramMessageLimit, htmlMarkup)
+
+	chats := make([]string, len(tn.chatIDs))
+	for i, chatID := range tn.chatIDs {
+		chats[i] = strconv.FormatInt(chatID, 10)
+	}
+
+	err := deliverEach(chats, delivered, func(i int) error {
+		return tn.send(ctx, tn.chatIDs[i], texts, attachments)
+	})
+	if err != nil {
+		return fmt.Errorf("telegramNotifier.deliver: %w", err)
+	}
+
+	return nil
+}
+
+func (tn *telegramNotifier) send(ctx context.Context, chatID int64, texts []string, attachments []attachment) error {
+	for _, text := range texts {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		msg := tgbotapi.NewMessage(chatID, text)
+		msg.ParseMode = tgbotapi.ModeHTML
+		_, err := tn.client.Send(msg)
+		if err != nil {
+			return fmt.Errorf("telegramNotifier.send: %w", err)
+		}
+	}
+
+	for _, att := range attachments {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		doc := tgbotapi.NewDocumentUpload(chatID, tgbotapi.FileBytes{Name: att.Name, Bytes: att.Content})
+		_, err := tn.client.Send(doc)
+		if err != nil {
+			return fmt.Errorf("telegramNotifier.send: %s: %w", att.Name, err)
+		}
+	}
+
+	return nil
+}
+
+func (tn *telegramNotifier) WithTemplate(title, body string) (Notifier, error) {
+	t, err := newTemplate(title, body, templateFormat{htmlTitle: true, htmlBody: true})
+	if err != nil {
+		return nil, fmt.Errorf("telegramNotifier.WithTemplate: %w", err)
+	}
+
+	c := *tn
+	c.template = t
+	return &c, nil
+}
+
+func (tn *telegramNotifier) buildMsgTitle(msg message) string {
+	return fmt.Sprintf("<b>%s</b>\n", escapeHtml(msg.Title))
 }
 
-func (tn *telegramNotifier) buildMsgBody(e entity.ErrorInfo) string {
+// buildMsgBody returns the body in blocks of complete markup, so that it can be
+// split into several messages.
+func (tn *telegramNotifier) buildMsgBody(msg message, attachments []attachment) []string {
 	var buffer bytes.Buffer
 
 	// Main error information
-	buffer.WriteString(fmt.Sprintf("<b>🔍 Environment:</b> %s\n", escapeHtml(tn.environment)))
-	buffer.WriteString(fmt.Sprintf("<b>🛠️ Service:</b> %s\n", escapeHtml(e.Service)))
-	buffer.WriteString(fmt.Sprintf("<b>🔄 Operation:</b> %s\n", escapeHtml(e.Operation)))
-	buffer.WriteString(fmt.Sprintf("<b>🏷️ Code:</b> %s\n", escapeHtml(e.Code)))
-	buffer.WriteString(fmt.Sprintf("<b>💬 Message:</b> %s\n", escapeHtml((e.Message))))
+	for _, f := range msg.Fields {
+		buffer.WriteString(fmt.Sprintf("<b>%s %s:</b>%s\n", f.Emoji, f.Label, f.text(escapeHtml)))
+	}
 
 	// Separator for Details section
-	buffer.WriteString("\n<b>📋 <i>Additional details</i></b>\n")
-
-	// Details section with only visible details
-	for k, v := range e.Details {
-		if v != "" {
-			if len(v) > 1000 {
-				v = v[:1000] + "..."
-			}
-			buffer.WriteString(fmt.Sprintf("<i>%s</i>: <code>%s</code>\n", escapeHtml(k), v))
-		}
+	if len(msg.Details) > 0 {
+		buffer.WriteString("\n<b>📋 <i>Additional details</i></b>\n")
+	}
+
+	blocks := []string{buffer.String()}
+
+	// Details section
+	for _, d := range msg.Details {
+		blocks = append(blocks, fmt.Sprintf("<i>%s</i>: <code>%s</code>\n", escapeHtml(d.Key), escapeHtmlCode(d.Value)))
+	}
+
+	if len(attachments) > 0 {
+		blocks = append(blocks, "\n📎 <i>Full values of long fields are attached as documents</i>\n")
 	}
 
-	return buffer.String()
+	return blocks
 }
@@ -0,0 +1,181 @@
+package notifier
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"io"
+	"text/template"
+	"time"
+	"unicode/utf8"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+// Templatable is implemented by notifiers whose messages can be rendered with
+// user defined templates.
+type Templatable interface {
+	// WithTemplate returns a copy of the notifier rendering the title and body
+	// of messages with the given Go templates. An empty template keeps the
+	// default rendering of its part.
+	WithTemplate(title, body string) (Notifier, error)
+}
+
+// TemplateData is the data templates are executed with.
+type TemplateData struct {
+	Environment string
+	Kind        entity.AlertKind
+	Reason      string
+	Error       entity.ErrorInfo
+	Issue       entity.Issue
+}
+
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// msgTemplate renders the title and body of a message. Nil parts are not templated.
+type msgTemplate struct {
+	title executor
+	body  executor
+}
+
+// templateFormat describes the markup a provider renders templates in.
+type templateFormat struct {
+	escape    func(string) string // Escapes values for the escape helper, nil if not needed
+	htmlTitle bool                // Parse the title with html/template, which escapes values itself
+	htmlBody  bool                // Parse the body with html/template
+}
+
+// newTemplate parses the templates and validates them by rendering a sample alert.
+func newTemplate(title, body string, format templateFormat) (*msgTemplate, error) {
+	escape := format.escape
+	if escape == nil {
+		escape = func(s string) string { return s }
+	}
+
+	funcs := map[string]any{
+		"truncate": truncateRunes,
+		"escape":   escape,
+		"humanize": humanizeTime,
+		"detail":   detailValue,
+	}
+
+	parse := func(name, text string, html bool) (executor, error) {
+		if text == "" {
+			return nil, nil
+		}
+		if html {
+			return htmltemplate.New(name).Funcs(htmltemplate.FuncMap(funcs)).Parse(text)
+		}
+		return template.New(name).Funcs(template.FuncMap(funcs)).Parse(text)
+	}
+
+	t := &msgTemplate{}
+
+	var err error
+	t.title, err = parse("title", title, format.htmlTitle)
+	if err != nil {
+		return nil, fmt.Errorf("newTemplate: %w", err)
+	}
+	t.body, err = parse("body", body, format.htmlBody)
+	if err != nil {
+		return nil, fmt.Errorf("newTemplate: %w", err)
+	}
+
+	_, _, err = t.render("production", sampleAlert(), "", "")
+	if err != nil {
+		return nil, fmt.Errorf("newTemplate: %w", err)
+	}
+
+	return t, nil
+}
+
+// render returns the rendered title and body. Parts without a template, or all
+// of them if t is nil, are replaced with the given defaults.
+func (t *msgTemplate) render(environment string, a entity.Alert, defaultTitle, defaultBody string) (string, string, error) {
+	if t == nil {
+		return defaultTitle, defaultBody, nil
+	}
+
+	data := TemplateData{Environment: environment, Kind: a.Kind, Reason: a.Reason, Error: a.Error, Issue: a.Issue}
+
+	title, body := defaultTitle, defaultBody
+	if t.title != nil {
+		var buffer bytes.Buffer
+		err := t.title.Execute(&buffer, data)
+		if err != nil {
+			return "", "", fmt.Errorf("msgTemplate.render: %w", err)
+		}
+		title = buffer.String()
+	}
+	if t.body != nil {
+		var buffer bytes.Buffer
+		err := t.body.Execute(&buffer, data)
+		if err != nil {
+			return "", "", fmt.Errorf("msgTemplate.render: %w", err)
+		}
+		body = buffer.String()
+	}
+
+	return title, body, nil
+}
+
+func sampleAlert() entity.Alert {
+	now := time.Now()
+	return entity.Alert{
+		Kind: entity.AlertKindError,
+		Error: entity.ErrorInfo{
+			ID:        "00000000-0000-0000-0000-000000000000",
+			Code:      "INTERNAL",
+			Message:   "sample error",
+			Details:   map[string]string{"stacktrace": "main.go:1"},
+			Service:   "sample-service",
+			Operation: "GET /sample",
+			CreatedAt: now,
+		},
+		Issue: entity.Issue{
+			ID:        "00000000-0000-0000-0000-000000000000",
+			Status:    entity.IssueStatusUnresolved,
+			FirstSeen: now.Add(-time.Hour),
+			LastSeen:  now,
+			Count:     1,
+		},
+	}
+}
+
+// truncateRunes shortens s to at most n runes, marking cut strings with "...".
+func truncateRunes(n int, s string) string {
+	if utf8.RuneCountInString(s) <= n {
+		return s
+	}
+	return string([]rune(s)[:n]) + "..."
+}
+
+// humanizeTime describes how long ago t was, e.g. "5 minutes ago".
+func humanizeTime(t time.Time) string {
+	d := time.Since(t)
+
+	plural := func(n int, unit string) string {
+		if n == 1 {
+			return fmt.Sprintf("1 %s ago", unit)
+		}
+		return fmt.Sprintf("%d %ss ago", n, unit)
+	}
+
+	switch {
+	case d < time.Minute:
+		return "just now"
+	case d < time.Hour:
+		return plural(int(d/time.Minute), "minute")
+	case d < 24*time.Hour:
+		return plural(int(d/time.Hour), "hour")
+	default:
+		return plural(int(d/(24*time.Hour)), "day")
+	}
+}
+
+// detailValue returns the detail of the error by key, empty if missing.
+func detailValue(e entity.ErrorInfo, key string) string {
+	return e.Details[key]
+}
@@ -1,27 +1,105 @@
 package notifier
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
 	"html"
+	"io"
+	"net/http"
 	"strings"
 )
 
+// markdownReplacer escapes special characters used in Markdown: *, _, `, ~, |
+var markdownReplacer = strings.NewReplacer(
+	"*", "\\*",
+	"_", "\\_",
+	"`", "\\`",
+	"~", "\\~",
+	"|", "\\|",
+)
+
 // escapeMarkdown escapes Discord Markdown special characters.
 func escapeMarkdown(in string) string {
-	// Escape special characters used in Markdown: *, _, `, ~, |
-	replacer := strings.NewReplacer(
-		"*", "\\*",
-		"_", "\\_",
-		"`", "\\`",
-		"~", "\\~",
-		"|", "\\|",
-	)
-	return replacer.Replace(replaceNewlines(in))
+	return markdownReplacer.Replace(replaceNewlines(in))
+}
+
+// escapeMarkdownText escapes Markdown special characters, keeping line breaks.
+func escapeMarkdownText(in string) string {
+	return markdownReplacer.Replace(in)
 }
 
 func escapeHtml(in string) string {
 	return html.EscapeString(replaceNewlines(in))
 }
 
+// escapeHtmlCode escapes text shown in an HTML code element, keeping line breaks.
+func escapeHtmlCode(in string) string {
+	return html.EscapeString(in)
+}
+
+// escapeCodeBlock keeps text from closing the Markdown code block it is shown
+// in, by breaking up backtick runs with zero width spaces.
+func escapeCodeBlock(in string) string {
+	out := strings.ReplaceAll(in, "``", "`\u200b`")
+	out = strings.ReplaceAll(out, "``", "`\u200b`") // Overlapping runs, e.g. "```"
+	if strings.HasSuffix(out, "`") || strings.HasPrefix(out, "`") {
+		out = "\u200b" + out + "\u200b"
+	}
+	return out
+}
+
 func replaceNewlines(in string) string {
 	return strings.ReplaceAll(in, "\n", "\\n")
 }
+
+// escapeSlack escapes the control characters of Slack mrkdwn.
+func escapeSlack(in string) string {
+	replacer := strings.NewReplacer(
+		"&", "&amp;",
+		"<", "&lt;",
+		">", "&gt;",
+	)
+	return replacer.Replace(in)
+}
+
+// postJSON posts v encoded as JSON and returns the response body,
+// failing on non 2xx statuses.
+func postJSON(ctx context.Context, client *http.Client, url string, headers map[string]string, v any) ([]byte, error) {
+	body, err := json.Marshal(v)
+	if err != nil {
+		return nil, fmt.Errorf("postJSON: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("postJSON: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	for k, v := range headers {
+		req.Header.Set(k, v)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("postJSON: %w", err)
+	}
+	defer resp.Body.Close()
+
+	respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<16))
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("postJSON: unexpected status %s: %s", resp.Status, respBody)
+	}
+
+	return respBody, nil
+}
+
+// webhookKeys returns the recipient keys of n webhooks: "webhook 1", "webhook 2", ...
+func webhookKeys(n int) []string {
+	keys := make([]string, n)
+	for i := range keys {
+		keys[i] = fmt.Sprintf("webhook %d", i+1)
+	}
+	return keys
+}
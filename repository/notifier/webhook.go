@@ -0,0 +1,268 @@
+package notifier
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+const (
+	webhookPayloadVersion = "1"
+
+	// WebhookSignatureHeader holds "sha256=" followed by the hex encoded
+	// HMAC-SHA256 of "<timestamp>.<body>" keyed with the webhook secret.
+	// Receivers should reject requests with stale timestamps to prevent replays.
+	WebhookSignatureHeader = "X-Sentinel-Signature"
+	WebhookTimestampHeader = "X-Sentinel-Timestamp" // Unix time in seconds
+)
+
+// WebhookConfig holds the settings of the webhook notifier.
+type WebhookConfig struct {
+	URLs    []string
+	Secret  string // Key of the request signatures, required
+	Headers map[string]string
+	Timeout time.Duration
+}
+
+type webhookNotifier struct {
+	client      *http.Client
+	cfg         WebhookConfig
+	environment string
+}
+
+func NewWebhookNotifier(cfg WebhookConfig, environment string) (*webhookNotifier, error) {
+	if len(cfg.URLs) == 0 {
+		return nil, fmt.Errorf("NewWebhookNotifier: at least one URL is required")
+	}
+	if cfg.Secret == "" {
+		return nil, fmt.Errorf("NewWebhookNotifier: secret is required")
+	}
+	if cfg.Timeout <= 0 {
+		return nil, fmt.Errorf("NewWebhookNotifier: timeout must be positive")
+	}
+
+	return &webhookNotifier{
+		client:      &http.Client{Timeout: cfg.Timeout},
+		cfg:         cfg,
+		environment: environment,
+	}, nil
+}
+
+type webhookPayload struct {
+	Version     string       `json:"version"`
+	Kind        string       `json:"kind"`   // "error", "regression", "condition" or "spike"
+	Reason      string       `json:"reason"` // Why the alert is sent, for condition and spike alerts
+	Environment string       `json:"environment"`
+	Error       webhookError `json:"error"`
+	Issue       webhookIssue `json:"issue"`
+}
+
+type webhookError struct {
+	ID              string            `json:"id"`
+	Code            string            `json:"code"`
+	Message         string            `json:"message"`
+	MessageTemplate string            `json:"message_template"`
+	Details         map[string]string `json:"details"`
+	Service         string            `json:"service"`
+	Operation       string            `json:"operation"`
+	CreatedAt       time.Time         `json:"created_at"`
+}
+
+type webhookIssue struct {
+	ID          string    `json:"id"`
+	Fingerprint string    `json:"fingerprint"`
+	Status      string    `json:"status"`
+	FirstSeen   time.Time `json:"first_seen"`
+	LastSeen    time.Time `json:"last_seen"`
+	Count       int64     `json:"count"`
+}
+
+// webhookDigestPayload is sent for digests, told apart from alerts by its kind.
+type webhookDigestPayload struct {
+	Version     string    `json:"version"`
+	Kind        string    `json:"kind"` // "digest"
+	Environment string    `json:"environment"`
+	Name        string    `json:"name"`
+	From        time.Time `json:"from"`
+	To          time.Time `json:"to"`
+
+	NewIssues       []webhookIssueCount      `json:"new_issues"`
+	TopIssues       []webhookIssueCount      `json:"top_issues"`
+	Increases       []webhookServiceIncrease `json:"increases"`
+	Unresolved      []webhookServiceCount    `json:"unresolved"`
+	UnresolvedTotal int64                    `json:"unresolved_total"`
+}
+
+type webhookIssueCount struct {
+	Issue     webhookIssue `json:"issue"`
+	Code      string       `json:"code"`
+	Message   string       `json:"message"`
+	Service   string       `json:"service"`
+	Operation string       `json:"operation"`
+	Count     int64        `json:"count"` // Errors in the period
+}
+
+type webhookServiceIncrease struct {
+	Service  string `json:"service"`
+	Count    int64  `json:"count"`
+	Previous int64  `json:"previous"`
+}
+
+type webhookServiceCount struct {
+	Service string `json:"service"`
+	Count   int64  `json:"count"`
+}
+
+func (wn *webhookNotifier) Notify(ctx context.Context, a entity.Alert) error {
+	err := wn.deliver(ctx, wn.buildPayload(a), a.Delivered)
+	if err != nil {
+		return fmt.Errorf("webhookNotifier.Notify: %w", err)
+	}
+	return nil
+}
+
+func (wn *webhookNotifier) NotifyDigest(ctx context.Context, d entity.Digest) error {
+	err := wn.deliver(ctx, wn.buildDigestPayload(d), nil)
+	if err != nil {
+		return fmt.Errorf("webhookNotifier.NotifyDigest: %w", err)
+	}
+	return nil
+}
+
+// deliver posts the payload to all URLs not yet delivered to, which are
+// told apart by their position.
+func (wn *webhookNotifier) deliver(ctx context.Context, payload any, delivered []string) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("webhookNotifier.deliver: %w", err)
+	}
+
+	err = deliverEach(webhookKeys(len(wn.cfg.URLs)), delivered, func(i int) error {
+		return wn.post(ctx, wn.cfg.URLs[i], body)
+	})
+	if err != nil {
+		return fmt.Errorf("webhookNotifier.deliver: %w", err)
+	}
+
+	return nil
+}
+
+func (wn *webhookNotifier) buildPayload(a entity.Alert) webhookPayload {
+	return webhookPayload{
+		Version:     webhookPayloadVersion,
+		Kind:        string(a.Kind),
+		Reason:      a.Reason,
+		Environment: wn.environment,
+		Error: webhookError{
+			ID:              a.Error.ID,
+			Code:            a.Error.Code,
+			Message:         a.Error.Message,
+			MessageTemplate: a.Error.MessageTemplate,
+			Details:         a.Error.Details,
+			Service:         a.Error.Service,
+			Operation:       a.Error.Operation,
+			CreatedAt:       a.Error.CreatedAt,
+		},
+		Issue: toWebhookIssue(a.Issue),
+	}
+}
+
+func (wn *webhookNotifier) buildDigestPayload(d entity.Digest) webhookDigestPayload {
+	issueCounts := func(issues []entity.IssueCount) []webhookIssueCount {
+		out := make([]webhookIssueCount, 0, len(issues))
+		for _, ic := range issues {
+			out = append(out, webhookIssueCount{
+				Issue:     toWebhookIssue(ic.Issue),
+				Code:      ic.Issue.Code,
+				Message:   ic.Issue.Message,
+				Service:   ic.Issue.Service,
+				Operation: ic.Issue.Operation,
+				Count:     ic.Count,
+			})
+		}
+		return out
+	}
+
+	increases := make([]webhookServiceIncrease, 0, len(d.Increases))
+	for _, inc := range d.Increases {
+		increases = append(increases, webhookServiceIncrease{Service: inc.Service, Count: inc.Count, Previous: inc.Previous})
+	}
+
+	unresolved := make([]webhookServiceCount, 0, len(d.Unresolved))
+	for _, c := range d.Unresolved {
+		unresolved = append(unresolved, webhookServiceCount{Service: c.Service, Count: c.Count})
+	}
+
+	return webhookDigestPayload{
+		Version:         webhookPayloadVersion,
+		Kind:            "digest",
+		Environment:     wn.environment,
+		Name:            d.Name,
+		From:            d.From,
+		To:              d.To,
+		NewIssues:       issueCounts(d.NewIssues),
+		TopIssues:       issueCounts(d.TopIssues),
+		Increases:       increases,
+		Unresolved:      unresolved,
+		UnresolvedTotal: d.UnresolvedTotal,
+	}
+}
+
+func toWebhookIssue(issue entity.Issue) webhookIssue {
+	return webhookIssue{
+		ID:          issue.ID,
+		Fingerprint: issue.Fingerprint,
+		Status:      string(issue.Status),
+		FirstSeen:   issue.FirstSeen,
+		LastSeen:    issue.LastSeen,
+		Count:       issue.Count,
+	}
+}
+
+func (wn *webhookNotifier) post(ctx context.Context, url string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("webhookNotifier.post: %w", err)
+	}
+
+	for k, v := range wn.cfg.Headers {
+		req.Header.Set(k, v)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "sentinel-webhook/"+webhookPayloadVersion)
+
+	timestamp := strconv.FormatInt(time.Now().Unix(), 10)
+	req.Header.Set(WebhookTimestampHeader, timestamp)
+	req.Header.Set(WebhookSignatureHeader, "sha256="+signWebhook(wn.cfg.Secret, timestamp, body))
+
+	resp, err := wn.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("webhookNotifier.post: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		respBody, _ := io.ReadAll(io.LimitReader(resp.Body, 1<<10))
+		return fmt.Errorf("webhookNotifier.post: unexpected status %s: %s", resp.Status, respBody)
+	}
+
+	return nil
+}
+
+func signWebhook(secret, timestamp string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(timestamp))
+	mac.Write([]byte("."))
+	mac.Write(body)
+	return hex.EncodeToString(mac.Sum(nil))
+}
@@ -0,0 +1,73 @@
+package notifier
+
+import (
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// Receivers reproduce the signature, so its format must not change
+func TestSignWebhook(t *testing.T) {
+	got := signWebhook("secret", "1700000000", []byte(`{"version":"1"}`))
+	want := "f0779f2556faa4190d475c20b01ce628d62c9484404828dbdbf632cdc7402cd5"
+	if got != want {
+		t.Errorf("signWebhook = %s, want %s", got, want)
+	}
+}
+
+func TestWebhookNotifierSignsRequests(t *testing.T) {
+	type request struct {
+		header http.Header
+		body   []byte
+	}
+	requests := make(chan request, 1)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		body, _ := io.ReadAll(r.Body)
+		requests <- request{header: r.Header, body: body}
+	}))
+	defer srv.Close()
+
+	wn, err := NewWebhookNotifier(WebhookConfig{
+		URLs:    []string{srv.URL},
+		Secret:  "secret",
+		Timeout: time.Second,
+	}, "production")
+	if err != nil {
+		t.Fatalf("NewWebhookNotifier: %v", err)
+	}
+
+	err = wn.Notify(context.Background(), testAlert())
+	if err != nil {
+		t.Fatalf("Notify: %v", err)
+	}
+	r := <-requests
+
+	timestamp := r.header.Get(WebhookTimestampHeader)
+	sent, err := strconv.ParseInt(timestamp, 10, 64)
+	if err != nil || time.Since(time.Unix(sent, 0)).Abs() > time.Minute {
+		t.Errorf("%s = %q, want the current Unix time", WebhookTimestampHeader, timestamp)
+	}
+
+	// What a receiver computes from the request
+	mac := hmac.New(sha256.New, []byte("secret"))
+	mac.Write([]byte(timestamp + "."))
+	mac.Write(r.body)
+	want := "sha256=" + hex.EncodeToString(mac.Sum(nil))
+	if got := r.header.Get(WebhookSignatureHeader); got != want {
+		t.Errorf("%s = %q, want %q", WebhookSignatureHeader, got, want)
+	}
+}
+
+func TestNewWebhookNotifierRequiresSecret(t *testing.T) {
+	_, err := NewWebhookNotifier(WebhookConfig{URLs: []string{"http://localhost"}, Timeout: time.Second}, "production")
+	if err == nil {
+		t.Error("NewWebhookNotifier accepted a config without a secret")
+	}
+}
@@ -0,0 +1,55 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+func (r *pgStore) CountConditionErrors(ctx context.Context, w entity.ConditionWindow, from, to time.Time) (int64, error) {
+	var count int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT COALESCE(sum(count), 0)
+		FROM condition_counts
+		WHERE condition = $1 AND service = $2 AND operation = $3 AND code = $4
+			AND bucket >= $5 AND bucket < $6;
+	`, w.Condition, w.Service, w.Operation, w.Code, from, to).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("pgStore.CountConditionErrors: %w", err)
+	}
+	return count, nil
+}
+
+func (r *pgStore) CountConditionUsers(ctx context.Context, w entity.ConditionWindow, since time.Time) (int64, error) {
+	var count int64
+	err := r.pool.QueryRow(ctx, `
+		SELECT count(*)
+		FROM condition_users
+		WHERE condition = $1 AND service = $2 AND operation = $3 AND code = $4
+			AND last_seen >= $5;
+	`, w.Condition, w.Service, w.Operation, w.Code, since).Scan(&count)
+	if err != nil {
+		return 0, fmt.Errorf("pgStore.CountConditionUsers: %w", err)
+	}
+	return count, nil
+}
+
+func (r *pgStore) PruneConditions(ctx context.Context, before time.Time) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM condition_counts WHERE bucket < $1;
+	`, before)
+	if err != nil {
+		return fmt.Errorf("pgStore.PruneConditions: %w", err)
+	}
+
+	_, err = r.pool.Exec(ctx, `
+		DELETE FROM condition_users WHERE last_seen < $1;
+	`, before)
+	if err != nil {
+		return fmt.Errorf("pgStore.PruneConditions: %w", err)
+	}
+
+	return nil
+}
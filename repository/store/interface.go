@@ -3,14 +3,86 @@ package store
 import (
 	"context"
 	"errors"
+	"time"
 
 	"github.com/code19m/sentinel/entity"
 )
 
-var ErrNotFound = errors.New("object not found")
+var (
+	ErrNotFound = errors.New("object not found")
+	// ErrStale is returned when updating an object that was changed by someone else meanwhile.
+	ErrStale = errors.New("object is stale")
+)
 
 type Store interface {
-	Add(ctx context.Context, e entity.ErrorInfo) error
+	// Migrate runs the migrations too slow to run at startup, index builds and
+	// backfills, without blocking writes. While one replica runs them, the
+	// others return at once.
+	Migrate(ctx context.Context) error
+	// Add saves the error and attaches it to the issue with the same fingerprint,
+	// creating the issue if it does not exist yet. A pending outbox entry for the
+	// error is created in the same transaction. It returns the updated issue.
+	Add(ctx context.Context, e entity.ErrorInfo) (entity.Issue, error)
+	// AddBatch adds all errors in a single transaction. Issues are returned
+	// in the same order as the errors.
+	AddBatch(ctx context.Context, es []entity.ErrorInfo) ([]entity.Issue, error)
 	Update(ctx context.Context, e entity.ErrorInfo) error
-	FindLast(ctx context.Context, service, operation string, alerted bool) (entity.ErrorInfo, error)
+	GetIssue(ctx context.Context, id string) (entity.Issue, error)
+	// SetIssueStatus changes the status of the issue. For ignored issues,
+	// ignoreUntil and ignoreCount more errors limit how long it stays ignored,
+	// nil and zero mean no limit.
+	SetIssueStatus(ctx context.Context, id string, status entity.IssueStatus, ignoreUntil *time.Time, ignoreCount int64) (entity.Issue, error)
+	// ListTopIssues returns up to limit issues with the most errors in the
+	// range, with their number of errors in it.
+	ListTopIssues(ctx context.Context, q entity.TopIssuesQuery, limit int) ([]entity.IssueCount, error)
+	// CountUnresolvedIssues returns the number of unresolved issues by service, the most first.
+	CountUnresolvedIssues(ctx context.Context) ([]entity.ServiceCount, error)
+
+	// ListErrors returns up to limit errors matching the filter, newest first.
+	ListErrors(ctx context.Context, filter entity.ErrorFilter, limit int) ([]entity.ErrorInfo, error)
+	GetError(ctx context.Context, id string) (entity.ErrorInfo, error)
+	// SearchErrors returns up to limit errors matching the search, the most
+	// relevant first.
+	SearchErrors(ctx context.Context, q entity.ErrorSearchQuery, limit int) ([]entity.ErrorSearchResult, error)
+	// GetErrorStats returns the error counts per bucket and group, ordered by
+	// bucket. Buckets without errors are omitted.
+	GetErrorStats(ctx context.Context, q entity.ErrorStatsQuery) ([]entity.ErrorStat, error)
+
+	// CountConditionErrors returns the errors counted in the window of an alert
+	// condition in the minute buckets from from up to to.
+	CountConditionErrors(ctx context.Context, w entity.ConditionWindow, from, to time.Time) (int64, error)
+	// CountConditionUsers returns the users seen in the window of an alert condition since since.
+	CountConditionUsers(ctx context.Context, w entity.ConditionWindow, since time.Time) (int64, error)
+	// PruneConditions deletes the counts and users of alert condition windows older than before.
+	PruneConditions(ctx context.Context, before time.Time) error
+
+	// AcquireAlert atomically takes the alert slot of key for owner. It fails to
+	// (returns false) if another owner took the slot less than cooldown ago.
+	// The owner holding the slot may acquire it again, so that a retried outbox
+	// entry keeps its slot; ExtendOutbox and UpdateOutbox keep two copies of an
+	// entry from both getting that far.
+	AcquireAlert(ctx context.Context, key, owner string, cooldown time.Duration) (bool, error)
+	// ReleaseAlert frees the alert slot of key if it is held by owner.
+	ReleaseAlert(ctx context.Context, key, owner string) error
+
+	// ClaimOutbox returns up to limit pending outbox entries due for delivery and
+	// postpones their next attempt by lease, so that concurrent dispatchers
+	// don't pick them up while they are being delivered.
+	ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEntry, error)
+	// ExtendOutbox renews the lease of the entries still pending at the attempt
+	// they were claimed at, and returns the IDs of those renewed.
+	ExtendOutbox(ctx context.Context, entries []entity.OutboxEntry, lease time.Duration) ([]string, error)
+	// QueueAlert adds a pending outbox entry of the kind and reason of the entry
+	// for its error. Alerts of new errors are queued by Add instead.
+	QueueAlert(ctx context.Context, entry entity.OutboxEntry) error
+	// UpdateOutbox records the outcome of a claimed entry. It fails with ErrStale
+	// if the entry is no longer pending at the attempt it was claimed at.
+	UpdateOutbox(ctx context.Context, entry entity.OutboxEntry) error
+	ListOutbox(ctx context.Context, status entity.OutboxStatus, limit int) ([]entity.OutboxEntry, error)
+	// RetryOutbox moves a dead or dropped outbox entry back to pending. Targets
+	// the entry was delivered to are kept, so only the others are retried.
+	RetryOutbox(ctx context.Context, id string) error
+	// PruneOutbox deletes the sent and suppressed outbox entries last updated
+	// before before. Dead and dropped entries are kept for retries.
+	PruneOutbox(ctx context.Context, before time.Time) error
 }
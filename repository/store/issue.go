@@ -0,0 +1,90 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+func (r *pgStore) SetIssueStatus(ctx context.Context, id string, status entity.IssueStatus, ignoreUntil *time.Time, ignoreCount int64) (entity.Issue, error) {
+	issue, err := scanIssue(r.pool.QueryRow(ctx, `
+		UPDATE issues
+		SET status = $2,
+			resolved_at = CASE WHEN $2 = $3 THEN now() END,
+			ignore_until = $4,
+			ignore_until_count = CASE WHEN $5::bigint > 0 THEN count + $5::bigint ELSE 0 END
+		WHERE id = $1
+		RETURNING `+issueColumns+`;
+	`, id, status, entity.IssueStatusResolved, ignoreUntil, ignoreCount))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return issue, ErrNotFound
+	}
+	if err != nil {
+		return issue, fmt.Errorf("pgStore.SetIssueStatus: %w", err)
+	}
+	return issue, nil
+}
+
+func (r *pgStore) ListTopIssues(ctx context.Context, q entity.TopIssuesQuery, limit int) ([]entity.IssueCount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+issueColumns+`, c.n
+		FROM (
+			SELECT issue_id, count(*) AS n
+			FROM errors
+			WHERE created_at >= $1 AND created_at < $2 AND issue_id IS NOT NULL
+			GROUP BY issue_id
+		) c
+		JOIN issues ON issues.id = c.issue_id
+		WHERE NOT $3 OR issues.first_seen >= $1
+		ORDER BY c.n DESC, issues.last_seen DESC
+		LIMIT $4;
+	`, q.From, q.To, q.NewOnly, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ListTopIssues: %w", err)
+	}
+
+	issues, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.IssueCount, error) {
+		ic := entity.IssueCount{}
+		i := &ic.Issue
+		err := row.Scan(
+			&i.ID, &i.Fingerprint, &i.Code, &i.Message, &i.Service, &i.Operation,
+			&i.Status, &i.FirstSeen, &i.LastSeen, &i.Count,
+			&i.ResolvedAt, &i.Ignore.Until, &i.Ignore.UntilCount,
+			&ic.Count,
+		)
+		return ic, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ListTopIssues: %w", err)
+	}
+
+	return issues, nil
+}
+
+func (r *pgStore) CountUnresolvedIssues(ctx context.Context) ([]entity.ServiceCount, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT service, count(*)
+		FROM issues
+		WHERE status = $1
+		GROUP BY service
+		ORDER BY 2 DESC, 1;
+	`, entity.IssueStatusUnresolved)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.CountUnresolvedIssues: %w", err)
+	}
+
+	counts, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.ServiceCount, error) {
+		c := entity.ServiceCount{}
+		err := row.Scan(&c.Service, &c.Count)
+		return c, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.CountUnresolvedIssues: %w", err)
+	}
+
+	return counts, nil
+}
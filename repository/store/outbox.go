@@ -0,0 +1,179 @@
+package store
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+const outboxColumns = `
+	o.id, o.kind, o.reason, o.status, o.attempts, o.next_attempt_at, o.last_error, o.delivered, o.created_at, o.updated_at,
+	e.id, e.code, e.message, e.message_template, e.details, e.service, e.operation,
+	e.issue_id, e.fingerprint, e.created_at, e.alerted
+`
+
+func scanOutboxEntry(row pgx.Row) (entity.OutboxEntry, error) {
+	o := entity.OutboxEntry{}
+	err := row.Scan(
+		&o.ID, &o.Kind, &o.Reason, &o.Status, &o.Attempts, &o.NextAttemptAt, &o.LastError, &o.Delivered, &o.CreatedAt, &o.UpdatedAt,
+		&o.Error.ID, &o.Error.Code, &o.Error.Message, &o.Error.MessageTemplate, &o.Error.Details,
+		&o.Error.Service, &o.Error.Operation, &o.Error.IssueID, &o.Error.Fingerprint,
+		&o.Error.CreatedAt, &o.Error.Alerted,
+	)
+	return o, err
+}
+
+func (r *pgStore) ClaimOutbox(ctx context.Context, limit int, lease time.Duration) ([]entity.OutboxEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		WITH o AS (
+			UPDATE alert_outbox
+			SET attempts = attempts + 1,
+				next_attempt_at = now() + $3 * interval '1 millisecond',
+				updated_at = now()
+			WHERE id IN (
+				SELECT id
+				FROM alert_outbox
+				WHERE status = $1 AND next_attempt_at <= now()
+				ORDER BY next_attempt_at
+				LIMIT $2
+				FOR UPDATE SKIP LOCKED
+			)
+			RETURNING *
+		)
+		SELECT `+outboxColumns+`
+		FROM o
+		JOIN errors e ON e.id = o.error_id
+		ORDER BY o.created_at;
+	`, entity.OutboxStatusPending, limit, lease.Milliseconds())
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ClaimOutbox: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.OutboxEntry, error) {
+		return scanOutboxEntry(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ClaimOutbox: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *pgStore) ExtendOutbox(ctx context.Context, entries []entity.OutboxEntry, lease time.Duration) ([]string, error) {
+	ids := make([]string, len(entries))
+	attempts := make([]int, len(entries))
+	for i, entry := range entries {
+		ids[i] = entry.ID
+		attempts[i] = entry.Attempts
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		UPDATE alert_outbox o
+		SET next_attempt_at = now() + $3 * interval '1 millisecond', updated_at = now()
+		FROM unnest($1::uuid[], $2::int[]) AS c(id, attempts)
+		WHERE o.id = c.id AND o.attempts = c.attempts AND o.status = $4
+		RETURNING o.id::text;
+	`, ids, attempts, lease.Milliseconds(), entity.OutboxStatusPending)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ExtendOutbox: %w", err)
+	}
+
+	extended, err := pgx.CollectRows(rows, pgx.RowTo[string])
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ExtendOutbox: %w", err)
+	}
+
+	return extended, nil
+}
+
+func (r *pgStore) QueueAlert(ctx context.Context, entry entity.OutboxEntry) error {
+	_, err := r.pool.Exec(ctx, `
+		INSERT INTO alert_outbox (id, error_id, kind, reason, status, attempts, next_attempt_at, last_error, created_at, updated_at)
+		VALUES ($1, $2, $3, $4, $5, 0, now(), '', now(), now());
+	`, entry.ID, entry.Error.ID, entry.Kind, entry.Reason, entity.OutboxStatusPending)
+	if err != nil {
+		return fmt.Errorf("pgStore.QueueAlert: %w", err)
+	}
+	return nil
+}
+
+func (r *pgStore) UpdateOutbox(ctx context.Context, entry entity.OutboxEntry) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE alert_outbox
+		SET status = $2, kind = $7, reason = $8, next_attempt_at = $4, last_error = $5, delivered = $9, updated_at = now()
+		WHERE id = $1 AND attempts = $3 AND status = $6;
+	`, entry.ID, entry.Status, entry.Attempts, entry.NextAttemptAt, entry.LastError, entity.OutboxStatusPending,
+		entry.Kind, entry.Reason, append([]string{}, entry.Delivered...)) // Not NULL
+	if err != nil {
+		return fmt.Errorf("pgStore.UpdateOutbox: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return fmt.Errorf("pgStore.UpdateOutbox: %w", ErrStale)
+	}
+	return nil
+}
+
+func (r *pgStore) ListOutbox(ctx context.Context, status entity.OutboxStatus, limit int) ([]entity.OutboxEntry, error) {
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+outboxColumns+`
+		FROM alert_outbox o
+		JOIN errors e ON e.id = o.error_id
+		WHERE o.status = $1
+		ORDER BY o.updated_at DESC
+		LIMIT $2;
+	`, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ListOutbox: %w", err)
+	}
+
+	entries, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.OutboxEntry, error) {
+		return scanOutboxEntry(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ListOutbox: %w", err)
+	}
+
+	return entries, nil
+}
+
+func (r *pgStore) RetryOutbox(ctx context.Context, id string) error {
+	tag, err := r.pool.Exec(ctx, `
+		UPDATE alert_outbox
+		SET status = $2, attempts = 0, next_attempt_at = now(), updated_at = now()
+		WHERE id = $1 AND status IN ($3, $4);
+	`, id, entity.OutboxStatusPending, entity.OutboxStatusDead, entity.OutboxStatusDropped)
+	if err != nil {
+		return fmt.Errorf("pgStore.RetryOutbox: %w", err)
+	}
+	if tag.RowsAffected() == 0 {
+		return ErrNotFound
+	}
+	return nil
+}
+
+// outboxPruneBatch is the number of entries PruneOutbox deletes per statement.
+const outboxPruneBatch = 10000
+
+func (r *pgStore) PruneOutbox(ctx context.Context, before time.Time) error {
+	// Deletes in batches to keep transactions short
+	for {
+		tag, err := r.pool.Exec(ctx, `
+			DELETE FROM alert_outbox
+			WHERE id IN (
+				SELECT id
+				FROM alert_outbox
+				WHERE status IN ($1, $2) AND updated_at < $3
+				LIMIT $4
+			);
+		`, entity.OutboxStatusSent, entity.OutboxStatusSuppressed, before, outboxPruneBatch)
+		if err != nil {
+			return fmt.Errorf("pgStore.PruneOutbox: %w", err)
+		}
+		if tag.RowsAffected() < outboxPruneBatch {
+			return nil
+		}
+	}
+}
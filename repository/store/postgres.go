@@ -1,12 +1,16 @@
 package store
 
 import (
+	"cmp"
 	"context"
 	"errors"
 	"fmt"
+	"maps"
+	"slices"
 	"time"
 
 	"github.com/code19m/sentinel/entity"
+	"github.com/google/uuid"
 	"github.com/jackc/pgx/v5"
 	"github.com/jackc/pgx/v5/pgxpool"
 )
@@ -28,15 +32,266 @@ type pgStore struct {
 	pool *pgxpool.Pool
 }
 
-func (r *pgStore) Add(ctx context.Context, e entity.ErrorInfo) error {
-	_, err := r.pool.Exec(ctx, `
-		INSERT INTO errors (id, code, message, details, service, operation, created_at, alerted)
-		VALUES ($1, $2, $3, $4, $5, $6, $7, $8);
-	`, e.ID, e.Code, e.Message, e.Details, e.Service, e.Operation, e.CreatedAt, e.Alerted)
+const issueColumns = `
+	id, fingerprint, code, message, service, operation, status, first_seen, last_seen, count,
+	resolved_at, ignore_until, ignore_until_count
+`
+
+// ignoreExpired tells in the issue upsert whether the error meets the ignore
+// condition of an ignored issue, which unresolves it and clears the condition.
+const ignoreExpired = `issues.status = $16 AND (
+	issues.ignore_until <= EXCLUDED.last_seen
+	OR (issues.ignore_until_count > 0 AND issues.count + 1 >= issues.ignore_until_count)
+)`
+
+// addErrorCTEs upserts the issue, inserts the error and queues its alert.
+const addErrorCTEs = `
+	WITH issue AS (
+		INSERT INTO issues (id, fingerprint, code, message, service, operation, status, first_seen, last_seen, count)
+		VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $8, 1)
+		ON CONFLICT (fingerprint) DO UPDATE
+		SET last_seen = GREATEST(issues.last_seen, EXCLUDED.last_seen),
+			count = issues.count + 1,
+			status = CASE
+				WHEN issues.status = $15 THEN $7
+				WHEN ` + ignoreExpired + ` THEN $7
+				ELSE issues.status
+			END,
+			ignore_until = CASE WHEN ` + ignoreExpired + ` THEN NULL ELSE issues.ignore_until END,
+			ignore_until_count = CASE WHEN ` + ignoreExpired + ` THEN 0 ELSE issues.ignore_until_count END,
+			resolved_at = NULL,
+			regressed_by = CASE WHEN issues.status = $15 THEN $9::uuid ELSE issues.regressed_by END
+		RETURNING ` + issueColumns + `, regressed_by
+	), inserted AS (
+		INSERT INTO errors (id, code, message, message_template, details, service, operation, issue_id, fingerprint, created_at, alerted)
+		VALUES ($9, $3, $4, $10, $11, $5, $6, (SELECT id FROM issue), $2, $8, $12)
+	), outbox AS (
+		INSERT INTO alert_outbox (id, error_id, kind, reason, status, attempts, next_attempt_at, last_error, created_at, updated_at)
+		SELECT $13, $9, CASE WHEN issue.regressed_by = $9::uuid THEN $17 ELSE $18 END, '', $14, 0, now(), '', now(), now()
+		FROM issue
+	)`
+
+// addQuery upserts the issue of an error, inserts the error attached to it,
+// queues its alert in the outbox and counts it in the per-minute rollup and
+// in the windows of its alert conditions in a single statement, returning the
+// updated issue.
+//
+// An error of a resolved issue reopens it and is queued as a regression alert.
+// The upsert records the error in regressed_by, so that concurrent errors of
+// the issue don't all see it resolved. An ignored issue is unresolved again by
+// the first error meeting its ignore condition.
+const addQuery = addErrorCTEs + `, counted AS (
+		INSERT INTO error_counts (bucket, service, operation, code, count)
+		VALUES (date_trunc('minute', $8::timestamptz), $5, $6, $3, 1)
+		ON CONFLICT (bucket, service, operation, code) DO UPDATE
+		SET count = error_counts.count + 1
+	), condition_counted AS (
+		INSERT INTO condition_counts (condition, service, operation, code, bucket, count)
+		SELECT c, $5, $6, $3, date_trunc('minute', $8::timestamptz), 1
+		FROM unnest($19::text[]) AS c
+		ON CONFLICT (condition, service, operation, code, bucket) DO UPDATE
+		SET count = condition_counts.count + 1
+	), condition_seen AS (
+		INSERT INTO condition_users (condition, service, operation, code, user_key, last_seen)
+		SELECT m.c, $5, $6, $3, m.u, $8
+		FROM unnest($19::text[], $20::text[]) AS m(c, u)
+		WHERE m.u <> ''
+		ON CONFLICT (condition, service, operation, code, user_key) DO UPDATE
+		SET last_seen = GREATEST(condition_users.last_seen, EXCLUDED.last_seen)
+	)
+	SELECT ` + issueColumns + `
+	FROM issue;
+`
+
+// addBatchQuery is addQuery without the counts, which AddBatch adds up for
+// the whole batch. It takes the first addBatchArgs arguments of addArgs.
+const addBatchQuery = addErrorCTEs + `
+	SELECT ` + issueColumns + `
+	FROM issue;
+`
+
+const addBatchArgs = 18
+
+func addArgs(e entity.ErrorInfo) []any {
+	conditions := make([]string, len(e.Conditions))
+	users := make([]string, len(e.Conditions))
+	for i, m := range e.Conditions {
+		conditions[i] = m.Condition
+		users[i] = m.User
+	}
+
+	return []any{
+		uuid.New().String(), e.Fingerprint, e.Code, e.Message, e.Service, e.Operation,
+		entity.IssueStatusUnresolved, e.CreatedAt,
+		e.ID, e.MessageTemplate, e.Details, e.Alerted,
+		uuid.New().String(), entity.OutboxStatusPending,
+		entity.IssueStatusResolved, entity.IssueStatusIgnored,
+		entity.AlertKindRegression, entity.AlertKindError,
+		conditions, users,
+	}
+}
+
+func scanIssue(row pgx.Row) (entity.Issue, error) {
+	issue := entity.Issue{}
+	err := row.Scan(
+		&issue.ID, &issue.Fingerprint, &issue.Code, &issue.Message, &issue.Service, &issue.Operation,
+		&issue.Status, &issue.FirstSeen, &issue.LastSeen, &issue.Count,
+		&issue.ResolvedAt, &issue.Ignore.Until, &issue.Ignore.UntilCount,
+	)
+	return issue, err
+}
+
+func (r *pgStore) Add(ctx context.Context, e entity.ErrorInfo) (entity.Issue, error) {
+	issue, err := scanIssue(r.pool.QueryRow(ctx, addQuery, addArgs(e)...))
 	if err != nil {
-		return fmt.Errorf("pgStore.Add: %w", err)
+		return issue, fmt.Errorf("pgStore.Add: %w", err)
+	}
+	return issue, nil
+}
+
+// AddBatch adds the errors in the order of their fingerprints and the counts
+// of the batch in one upsert per table in key order, so that concurrent
+// batches lock the same rows in the same order rather than deadlock.
+func (r *pgStore) AddBatch(ctx context.Context, es []entity.ErrorInfo) ([]entity.Issue, error) {
+	order := make([]int, len(es))
+	for i := range order {
+		order[i] = i
+	}
+	slices.SortStableFunc(order, func(a, b int) int {
+		return cmp.Compare(es[a].Fingerprint, es[b].Fingerprint)
+	})
+
+	tx, err := r.pool.Begin(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.AddBatch: %w", err)
+	}
+	defer tx.Rollback(ctx)
+
+	batch := &pgx.Batch{}
+	for _, i := range order {
+		batch.Queue(addBatchQuery, addArgs(es[i])[:addBatchArgs]...)
+	}
+	queueCounts(batch, es)
+
+	results := tx.SendBatch(ctx, batch)
+	issues := make([]entity.Issue, len(es))
+	for _, i := range order {
+		issues[i], err = scanIssue(results.QueryRow())
+		if err != nil {
+			results.Close()
+			return nil, fmt.Errorf("pgStore.AddBatch: %w", err)
+		}
+	}
+
+	err = results.Close()
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.AddBatch: %w", err)
+	}
+
+	err = tx.Commit(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.AddBatch: %w", err)
+	}
+
+	return issues, nil
+}
+
+type countKey struct {
+	condition string
+	service   string
+	operation string
+	code      string
+	bucket    time.Time
+	user      string
+}
+
+func compareCountKeys(a, b countKey) int {
+	return cmp.Or(
+		cmp.Compare(a.condition, b.condition),
+		cmp.Compare(a.service, b.service),
+		cmp.Compare(a.operation, b.operation),
+		cmp.Compare(a.code, b.code),
+		a.bucket.Compare(b.bucket),
+		cmp.Compare(a.user, b.user),
+	)
+}
+
+// queueCounts queues the upserts of the rollup and condition window counts of
+// the errors, added up per row and sorted by key.
+func queueCounts(batch *pgx.Batch, es []entity.ErrorInfo) {
+	errorCounts := make(map[countKey]int64)
+	conditionCounts := make(map[countKey]int64)
+	conditionUsers := make(map[countKey]time.Time)
+	for _, e := range es {
+		bucket := e.CreatedAt.Truncate(time.Minute)
+		errorCounts[countKey{service: e.Service, operation: e.Operation, code: e.Code, bucket: bucket}]++
+
+		for _, m := range e.Conditions {
+			key := countKey{condition: m.Condition, service: e.Service, operation: e.Operation, code: e.Code, bucket: bucket}
+			conditionCounts[key]++
+
+			if m.User != "" {
+				key.bucket, key.user = time.Time{}, m.User
+				if e.CreatedAt.After(conditionUsers[key]) {
+					conditionUsers[key] = e.CreatedAt
+				}
+			}
+		}
+	}
+
+	var conditions, services, operations, codes, users []string
+	var buckets, lastSeen []time.Time
+	var counts []int64
+
+	for _, k := range slices.SortedFunc(maps.Keys(errorCounts), compareCountKeys) {
+		buckets = append(buckets, k.bucket)
+		services = append(services, k.service)
+		operations = append(operations, k.operation)
+		codes = append(codes, k.code)
+		counts = append(counts, errorCounts[k])
+	}
+	batch.Queue(`
+		INSERT INTO error_counts (bucket, service, operation, code, count)
+		SELECT * FROM unnest($1::timestamptz[], $2::text[], $3::text[], $4::text[], $5::bigint[])
+		ON CONFLICT (bucket, service, operation, code) DO UPDATE
+		SET count = error_counts.count + EXCLUDED.count;
+	`, buckets, services, operations, codes, counts)
+
+	if len(conditionCounts) > 0 {
+		buckets, services, operations, codes, counts = nil, nil, nil, nil, nil
+		for _, k := range slices.SortedFunc(maps.Keys(conditionCounts), compareCountKeys) {
+			conditions = append(conditions, k.condition)
+			services = append(services, k.service)
+			operations = append(operations, k.operation)
+			codes = append(codes, k.code)
+			buckets = append(buckets, k.bucket)
+			counts = append(counts, conditionCounts[k])
+		}
+		batch.Queue(`
+			INSERT INTO condition_counts (condition, service, operation, code, bucket, count)
+			SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::timestamptz[], $6::bigint[])
+			ON CONFLICT (condition, service, operation, code, bucket) DO UPDATE
+			SET count = condition_counts.count + EXCLUDED.count;
+		`, conditions, services, operations, codes, buckets, counts)
+	}
+
+	if len(conditionUsers) > 0 {
+		conditions, services, operations, codes = nil, nil, nil, nil
+		for _, k := range slices.SortedFunc(maps.Keys(conditionUsers), compareCountKeys) {
+			conditions = append(conditions, k.condition)
+			services = append(services, k.service)
+			operations = append(operations, k.operation)
+			codes = append(codes, k.code)
+			users = append(users, k.user)
+			lastSeen = append(lastSeen, conditionUsers[k])
+		}
+		batch.Queue(`
+			INSERT INTO condition_users (condition, service, operation, code, user_key, last_seen)
+			SELECT * FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[], $6::timestamptz[])
+			ON CONFLICT (condition, service, operation, code, user_key) DO UPDATE
+			SET last_seen = GREATEST(condition_users.last_seen, EXCLUDED.last_seen);
+		`, conditions, services, operations, codes, users, lastSeen)
 	}
-	return nil
 }
 
 func (r *pgStore) Update(ctx context.Context, e entity.ErrorInfo) error {
@@ -51,28 +306,51 @@ func (r *pgStore) Update(ctx context.Context, e entity.ErrorInfo) error {
 	return nil
 }
 
-func (r *pgStore) FindLast(ctx context.Context, service, operation string, alerted bool) (entity.ErrorInfo, error) {
-	e := entity.ErrorInfo{}
-
-	row := r.pool.QueryRow(ctx, `
-		SELECT id, code, message, details, service, operation, created_at, alerted
-		FROM errors
-		WHERE service = $1 AND operation = $2 AND alerted = $3
-		ORDER BY created_at DESC
-		LIMIT 1;
-	`, service, operation, alerted)
+func (r *pgStore) GetIssue(ctx context.Context, id string) (entity.Issue, error) {
+	issue, err := scanIssue(r.pool.QueryRow(ctx, `
+		SELECT `+issueColumns+`
+		FROM issues
+		WHERE id = $1;
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return issue, ErrNotFound
+	}
+	if err != nil {
+		return issue, fmt.Errorf("pgStore.GetIssue: %w", err)
+	}
+	return issue, nil
+}
 
-	err := row.Scan(
-		&e.ID, &e.Code, &e.Message, &e.Details, &e.Service, &e.Operation, &e.CreatedAt, &e.Alerted,
-	)
+func (r *pgStore) AcquireAlert(ctx context.Context, key, owner string, cooldown time.Duration) (bool, error) {
+	// The conflicting row is locked by the upsert, so concurrent callers are
+	// serialized and only one of them sees the cooldown expired
+	err := r.pool.QueryRow(ctx, `
+		INSERT INTO alert_state (key, owner_id, alerted_at)
+		VALUES ($1, $2, now())
+		ON CONFLICT (key) DO UPDATE
+		SET owner_id = EXCLUDED.owner_id, alerted_at = EXCLUDED.alerted_at
+		WHERE alert_state.owner_id = EXCLUDED.owner_id
+			OR alert_state.alerted_at <= now() - $3 * interval '1 millisecond'
+		RETURNING key;
+	`, key, owner, cooldown.Milliseconds()).Scan(&key)
 	if errors.Is(err, pgx.ErrNoRows) {
-		return e, ErrNotFound
+		return false, nil
 	}
 	if err != nil {
-		return e, fmt.Errorf("pgStore.FindLast: %w", err)
+		return false, fmt.Errorf("pgStore.AcquireAlert: %w", err)
 	}
+	return true, nil
+}
 
-	return e, nil
+func (r *pgStore) ReleaseAlert(ctx context.Context, key, owner string) error {
+	_, err := r.pool.Exec(ctx, `
+		DELETE FROM alert_state
+		WHERE key = $1 AND owner_id = $2;
+	`, key, owner)
+	if err != nil {
+		return fmt.Errorf("pgStore.ReleaseAlert: %w", err)
+	}
+	return nil
 }
 
 func (r *pgStore) initDB(ctx context.Context) error {
@@ -89,11 +367,144 @@ func (r *pgStore) initDB(ctx context.Context) error {
 			alerted BOOLEAN NOT NULL
 		);
 
+		CREATE TABLE IF NOT EXISTS issues (
+			id UUID PRIMARY KEY,
+			fingerprint TEXT NOT NULL UNIQUE,
+			code TEXT NOT NULL,
+			message TEXT NOT NULL,
+			service TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			status TEXT NOT NULL,
+			first_seen TIMESTAMPTZ NOT NULL,
+			last_seen TIMESTAMPTZ NOT NULL,
+			count BIGINT NOT NULL
+		);
+
+		ALTER TABLE issues ADD COLUMN IF NOT EXISTS resolved_at TIMESTAMPTZ;
+		ALTER TABLE issues ADD COLUMN IF NOT EXISTS ignore_until TIMESTAMPTZ;
+		ALTER TABLE issues ADD COLUMN IF NOT EXISTS ignore_until_count BIGINT NOT NULL DEFAULT 0;
+		ALTER TABLE issues ADD COLUMN IF NOT EXISTS regressed_by UUID;
+
+		ALTER TABLE errors ADD COLUMN IF NOT EXISTS issue_id UUID REFERENCES issues (id);
+		ALTER TABLE errors ADD COLUMN IF NOT EXISTS fingerprint TEXT NOT NULL DEFAULT '';
+		ALTER TABLE errors ADD COLUMN IF NOT EXISTS message_template TEXT NOT NULL DEFAULT '';
+
 		CREATE INDEX IF NOT EXISTS idx_errors_service_operation_alerted
 		ON errors (service, operation, alerted);
 
 		CREATE INDEX IF NOT EXISTS idx_errors_created_at
 		ON errors (created_at);
+
+		-- Full-text search over the message and detail values. A generated
+		-- column would rewrite the whole table under an exclusive lock, so
+		-- the column is filled by a trigger for new errors and in batches by
+		-- Migrate for existing ones
+		ALTER TABLE errors ADD COLUMN IF NOT EXISTS search TSVECTOR;
+
+		CREATE OR REPLACE FUNCTION errors_search(message TEXT, details JSONB) RETURNS TSVECTOR AS $$
+			SELECT setweight(to_tsvector('english', message), 'A') ||
+				setweight(jsonb_to_tsvector('english', details, '["string"]'), 'B')
+		$$ LANGUAGE SQL IMMUTABLE;
+
+		CREATE OR REPLACE FUNCTION errors_set_search() RETURNS TRIGGER AS $$
+		BEGIN
+			NEW.search := errors_search(NEW.message, NEW.details);
+			RETURN NEW;
+		END
+		$$ LANGUAGE plpgsql;
+
+		-- Creating a trigger locks the table, so it is only created once
+		DO $$
+		BEGIN
+			IF NOT EXISTS (
+				SELECT 1 FROM pg_trigger
+				WHERE tgrelid = 'errors'::regclass AND tgname = 'errors_search'
+			) THEN
+				CREATE TRIGGER errors_search BEFORE INSERT OR UPDATE OF message, details ON errors
+				FOR EACH ROW EXECUTE FUNCTION errors_set_search();
+			END IF;
+		END $$;
+
+		CREATE TABLE IF NOT EXISTS alert_outbox (
+			id UUID PRIMARY KEY,
+			error_id UUID NOT NULL REFERENCES errors (id),
+			status TEXT NOT NULL,
+			attempts INT NOT NULL,
+			next_attempt_at TIMESTAMPTZ NOT NULL,
+			last_error TEXT NOT NULL,
+			created_at TIMESTAMPTZ NOT NULL,
+			updated_at TIMESTAMPTZ NOT NULL
+		);
+
+		ALTER TABLE alert_outbox ADD COLUMN IF NOT EXISTS kind TEXT NOT NULL DEFAULT 'error';
+		ALTER TABLE alert_outbox ADD COLUMN IF NOT EXISTS reason TEXT NOT NULL DEFAULT '';
+		ALTER TABLE alert_outbox ADD COLUMN IF NOT EXISTS delivered TEXT[] NOT NULL DEFAULT '{}';
+
+		CREATE INDEX IF NOT EXISTS idx_alert_outbox_status_next_attempt_at
+		ON alert_outbox (status, next_attempt_at);
+
+		CREATE INDEX IF NOT EXISTS idx_alert_outbox_status_updated_at
+		ON alert_outbox (status, updated_at);
+
+		-- Errors per minute, the source of error statistics
+		CREATE TABLE IF NOT EXISTS error_counts (
+			bucket TIMESTAMPTZ NOT NULL,
+			service TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			code TEXT NOT NULL,
+			count BIGINT NOT NULL,
+			PRIMARY KEY (bucket, service, operation, code)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_error_counts_service_bucket
+		ON error_counts (service, bucket);
+
+		-- Progress of the backfills run by Migrate, rows created before until
+		-- are backfilled, up to done_until so far
+		CREATE TABLE IF NOT EXISTS backfills (
+			name TEXT PRIMARY KEY,
+			until TIMESTAMPTZ NOT NULL,
+			done_until TIMESTAMPTZ
+		);
+
+		-- Errors are counted as they are added from the first start with the
+		-- rollup, older ones are counted by Migrate
+		INSERT INTO backfills (name, until) VALUES ('error_counts', now())
+		ON CONFLICT DO NOTHING;
+
+		-- Errors per minute in the windows of alert conditions
+		CREATE TABLE IF NOT EXISTS condition_counts (
+			condition TEXT NOT NULL,
+			service TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			code TEXT NOT NULL,
+			bucket TIMESTAMPTZ NOT NULL,
+			count BIGINT NOT NULL,
+			PRIMARY KEY (condition, service, operation, code, bucket)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_condition_counts_bucket
+		ON condition_counts (bucket);
+
+		-- Users seen last in the windows of distinct_users alert conditions
+		CREATE TABLE IF NOT EXISTS condition_users (
+			condition TEXT NOT NULL,
+			service TEXT NOT NULL,
+			operation TEXT NOT NULL,
+			code TEXT NOT NULL,
+			user_key TEXT NOT NULL,
+			last_seen TIMESTAMPTZ NOT NULL,
+			PRIMARY KEY (condition, service, operation, code, user_key)
+		);
+
+		CREATE INDEX IF NOT EXISTS idx_condition_users_last_seen
+		ON condition_users (last_seen);
+
+		CREATE TABLE IF NOT EXISTS alert_state (
+			key TEXT PRIMARY KEY,
+			owner_id TEXT NOT NULL,
+			alerted_at TIMESTAMPTZ NOT NULL
+		);
 	`)
 	if err != nil {
 		return fmt.Errorf("pgStore.initDB: %w", err)
@@ -101,3 +512,138 @@ func (r *pgStore) initDB(ctx context.Context) error {
 
 	return nil
 }
+
+// errorIndexes are the indexes on errors added after it was created. Migrate
+// builds them concurrently, a plain build would block writes until done.
+var errorIndexes = []struct{ name, on string }{
+	{"idx_errors_issue_id_alerted", "errors (issue_id, alerted, created_at)"},
+	// Listing errors by filter, newest first
+	{"idx_errors_created_at_id", "errors (created_at, id)"},
+	{"idx_errors_service_operation_created_at_id", "errors (service, operation, created_at, id)"},
+	{"idx_errors_code_created_at_id", "errors (code, created_at, id)"},
+}
+
+// searchIndex is built once the search column is backfilled, so the backfill
+// does not have to update it.
+var searchIndex = []struct{ name, on string }{
+	{"idx_errors_search", "errors USING GIN (search)"},
+}
+
+// migrateLockKey identifies the advisory lock held while migrating.
+const migrateLockKey = 0x73656e74696e656c // "sentinel"
+
+func (r *pgStore) Migrate(ctx context.Context) error {
+	conn, err := r.pool.Acquire(ctx)
+	if err != nil {
+		return fmt.Errorf("pgStore.Migrate: %w", err)
+	}
+
+	var locked bool
+	err = conn.QueryRow(ctx, `SELECT pg_try_advisory_lock($1);`, migrateLockKey).Scan(&locked)
+	if err != nil {
+		conn.Release()
+		return fmt.Errorf("pgStore.Migrate: %w", err)
+	}
+	if !locked {
+		conn.Release()
+		return nil
+	}
+	defer func() {
+		_, err := conn.Exec(context.Background(), `SELECT pg_advisory_unlock($1);`, migrateLockKey)
+		if err != nil {
+			// The lock is held by the session, closing it releases the lock
+			conn.Conn().Close(context.Background())
+		}
+		conn.Release()
+	}()
+
+	err = r.createIndexes(ctx, errorIndexes)
+	if err != nil {
+		return fmt.Errorf("pgStore.Migrate: %w", err)
+	}
+	err = r.backfillErrorCounts(ctx)
+	if err != nil {
+		return fmt.Errorf("pgStore.Migrate: %w", err)
+	}
+	err = r.backfillSearch(ctx)
+	if err != nil {
+		return fmt.Errorf("pgStore.Migrate: %w", err)
+	}
+	err = r.createIndexes(ctx, searchIndex)
+	if err != nil {
+		return fmt.Errorf("pgStore.Migrate: %w", err)
+	}
+
+	return nil
+}
+
+func (r *pgStore) createIndexes(ctx context.Context, indexes []struct{ name, on string }) error {
+	for _, idx := range indexes {
+		// An interrupted concurrent build leaves an invalid index behind
+		var valid bool
+		err := r.pool.QueryRow(ctx, `
+			SELECT indisvalid FROM pg_index WHERE indexrelid = to_regclass($1);
+		`, idx.name).Scan(&valid)
+		if err == nil && valid {
+			continue
+		}
+		if err == nil {
+			_, err = r.pool.Exec(ctx, `DROP INDEX CONCURRENTLY IF EXISTS `+idx.name+`;`)
+		}
+		if err != nil && !errors.Is(err, pgx.ErrNoRows) {
+			return fmt.Errorf("pgStore.createIndexes: %w", err)
+		}
+
+		_, err = r.pool.Exec(ctx, `CREATE INDEX CONCURRENTLY IF NOT EXISTS `+idx.name+` ON `+idx.on+`;`)
+		if err != nil {
+			return fmt.Errorf("pgStore.createIndexes: %w", err)
+		}
+	}
+	return nil
+}
+
+// countsBackfillStep is the range of errors backfillErrorCounts counts per statement.
+const countsBackfillStep = time.Hour
+
+// backfillErrorCounts counts the errors saved before the rollup existed.
+func (r *pgStore) backfillErrorCounts(ctx context.Context) error {
+	// Starts from the earliest error unless resumed
+	var until, from time.Time
+	err := r.pool.QueryRow(ctx, `
+		SELECT until, COALESCE(done_until, (SELECT min(created_at) FROM errors), until)
+		FROM backfills
+		WHERE name = 'error_counts';
+	`).Scan(&until, &from)
+	if err != nil {
+		return fmt.Errorf("pgStore.backfillErrorCounts: %w", err)
+	}
+
+	// Each step records its progress in the same statement, so a restart
+	// neither skips nor counts errors twice
+	for from.Before(until) {
+		to := from.Add(countsBackfillStep)
+		if to.After(until) {
+			to = until
+		}
+
+		_, err := r.pool.Exec(ctx, `
+			WITH counted AS (
+				INSERT INTO error_counts (bucket, service, operation, code, count)
+				SELECT date_trunc('minute', created_at), service, operation, code, count(*)
+				FROM errors
+				WHERE created_at >= $1 AND created_at < $2
+				GROUP BY 1, 2, 3, 4
+				ON CONFLICT (bucket, service, operation, code) DO UPDATE
+				SET count = error_counts.count + EXCLUDED.count
+			)
+			UPDATE backfills SET done_until = $2
+			WHERE name = 'error_counts';
+		`, from, to)
+		if err != nil {
+			return fmt.Errorf("pgStore.backfillErrorCounts: %w", err)
+		}
+		from = to
+	}
+
+	return nil
+}
@@ -0,0 +1,281 @@
+package store
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"html"
+	"strings"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/jackc/pgx/v5"
+)
+
+// Errors saved before issues were introduced have no issue_id
+const errorColumns = `
+	id, code, message, message_template, details, service, operation,
+	COALESCE(issue_id::text, ''), fingerprint, created_at, alerted
+`
+
+func scanError(row pgx.Row) (entity.ErrorInfo, error) {
+	e := entity.ErrorInfo{}
+	err := row.Scan(
+		&e.ID, &e.Code, &e.Message, &e.MessageTemplate, &e.Details, &e.Service, &e.Operation,
+		&e.IssueID, &e.Fingerprint, &e.CreatedAt, &e.Alerted,
+	)
+	return e, err
+}
+
+func (r *pgStore) ListErrors(ctx context.Context, filter entity.ErrorFilter, limit int) ([]entity.ErrorInfo, error) {
+	// Only set filters become conditions, so that the planner can pick the index
+	// matching them instead of a generic plan
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if filter.Service != "" {
+		conds = append(conds, "service = "+arg(filter.Service))
+	}
+	if filter.Operation != "" {
+		conds = append(conds, "operation = "+arg(filter.Operation))
+	}
+	if filter.Code != "" {
+		conds = append(conds, "code = "+arg(filter.Code))
+	}
+	if !filter.From.IsZero() {
+		conds = append(conds, "created_at >= "+arg(filter.From))
+	}
+	if !filter.To.IsZero() {
+		conds = append(conds, "created_at < "+arg(filter.To))
+	}
+	if filter.Alerted != nil {
+		conds = append(conds, "alerted = "+arg(*filter.Alerted))
+	}
+	if filter.After != nil {
+		conds = append(conds, fmt.Sprintf("(created_at, id) < (%s, %s)", arg(filter.After.CreatedAt), arg(filter.After.ID)))
+	}
+
+	where := ""
+	if len(conds) > 0 {
+		where = "WHERE " + strings.Join(conds, " AND ")
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+errorColumns+`
+		FROM errors
+		`+where+`
+		ORDER BY created_at DESC, id DESC
+		LIMIT `+arg(limit)+`;
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ListErrors: %w", err)
+	}
+
+	es, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.ErrorInfo, error) {
+		return scanError(row)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.ListErrors: %w", err)
+	}
+
+	return es, nil
+}
+
+func (r *pgStore) GetError(ctx context.Context, id string) (entity.ErrorInfo, error) {
+	e, err := scanError(r.pool.QueryRow(ctx, `
+		SELECT `+errorColumns+`
+		FROM errors
+		WHERE id = $1;
+	`, id))
+	if errors.Is(err, pgx.ErrNoRows) {
+		return e, ErrNotFound
+	}
+	if err != nil {
+		return e, fmt.Errorf("pgStore.GetError: %w", err)
+	}
+	return e, nil
+}
+
+func (r *pgStore) SearchErrors(ctx context.Context, q entity.ErrorSearchQuery, limit int) ([]entity.ErrorSearchResult, error) {
+	conds := []string{"e.search @@ q"}
+	args := []any{q.Query}
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	if q.Service != "" {
+		conds = append(conds, "e.service = "+arg(q.Service))
+	}
+	if !q.From.IsZero() {
+		conds = append(conds, "e.created_at >= "+arg(q.From))
+	}
+	if !q.To.IsZero() {
+		conds = append(conds, "e.created_at < "+arg(q.To))
+	}
+
+	// Snippets are only built for the returned errors, ts_headline is costly.
+	// Matches are delimited by control characters, stripped from the text
+	// beforehand, and turned into tags once the snippet is escaped.
+	rows, err := r.pool.Query(ctx, `
+		WITH matches AS (
+			SELECT e.*, q, ts_rank_cd(e.search, q) AS rank
+			FROM errors e, websearch_to_tsquery('english', $1) AS q
+			WHERE `+strings.Join(conds, " AND ")+`
+			ORDER BY rank DESC, e.created_at DESC
+			LIMIT `+arg(limit)+`
+		)
+		SELECT `+errorColumns+`, rank::float8, ts_headline('english',
+			translate(message || ' ' || COALESCE((
+				SELECT string_agg(v #>> '{}', ' ')
+				FROM jsonb_path_query(details, 'strict $.** ? (@.type() == "string")') AS v
+			), ''), chr(2) || chr(3), '  '),
+			q, 'StartSel=' || chr(2) || ', StopSel=' || chr(3) || ', MaxFragments=3, MinWords=5, MaxWords=20')
+		FROM matches
+		ORDER BY rank DESC, created_at DESC;
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.SearchErrors: %w", err)
+	}
+
+	results, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.ErrorSearchResult, error) {
+		res := entity.ErrorSearchResult{}
+		e := &res.Error
+		err := row.Scan(
+			&e.ID, &e.Code, &e.Message, &e.MessageTemplate, &e.Details, &e.Service, &e.Operation,
+			&e.IssueID, &e.Fingerprint, &e.CreatedAt, &e.Alerted,
+			&res.Rank, &res.Snippet,
+		)
+		res.Snippet = snippetMarks.Replace(html.EscapeString(res.Snippet))
+		return res, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.SearchErrors: %w", err)
+	}
+
+	return results, nil
+}
+
+// snippetMarks turns the match delimiters of ts_headline into tags.
+var snippetMarks = strings.NewReplacer("\x02", "<mark>", "\x03", "</mark>")
+
+// searchBackfillBatch is the number of errors backfillSearch scans per update.
+const searchBackfillBatch = 1000
+
+// backfillSearch fills the search column of errors saved before it existed.
+func (r *pgStore) backfillSearch(ctx context.Context) error {
+	var pending bool
+	err := r.pool.QueryRow(ctx, `SELECT EXISTS (SELECT 1 FROM errors WHERE search IS NULL);`).Scan(&pending)
+	if err != nil {
+		return fmt.Errorf("pgStore.backfillSearch: %w", err)
+	}
+	if !pending {
+		return nil
+	}
+
+	// Walks the errors in index order so each batch is a range scan, and keeps
+	// transactions short so inserts are not blocked
+	var (
+		lastCreatedAt time.Time
+		lastID        = "00000000-0000-0000-0000-000000000000"
+	)
+	for {
+		err := r.pool.QueryRow(ctx, `
+			WITH batch AS (
+				SELECT id, created_at FROM errors
+				WHERE (created_at, id) > ($1, $2::uuid)
+				ORDER BY created_at, id
+				LIMIT $3
+			), filled AS (
+				UPDATE errors e SET search = errors_search(e.message, e.details)
+				FROM batch
+				WHERE e.id = batch.id AND e.search IS NULL
+			)
+			SELECT created_at, id::text FROM batch
+			ORDER BY created_at DESC, id DESC
+			LIMIT 1;
+		`, lastCreatedAt, lastID, searchBackfillBatch).Scan(&lastCreatedAt, &lastID)
+		if errors.Is(err, pgx.ErrNoRows) {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("pgStore.backfillSearch: %w", err)
+		}
+	}
+}
+
+func (r *pgStore) GetErrorStats(ctx context.Context, q entity.ErrorStatsQuery) ([]entity.ErrorStat, error) {
+	var (
+		conds []string
+		args  []any
+	)
+	arg := func(v any) string {
+		args = append(args, v)
+		return fmt.Sprintf("$%d", len(args))
+	}
+
+	// The rollup has minute buckets, date_bin merges them into buckets of the
+	// interval aligned to the start of the range
+	interval, from := arg(q.Interval.Milliseconds()), arg(q.From)
+	bucket := fmt.Sprintf("date_bin(%s * interval '1 millisecond', bucket, %s::timestamptz)", interval, from)
+
+	conds = append(conds, "bucket >= "+from, "bucket < "+arg(q.To))
+	if q.Service != "" {
+		conds = append(conds, "service = "+arg(q.Service))
+	}
+	if q.Operation != "" {
+		conds = append(conds, "operation = "+arg(q.Operation))
+	}
+	if q.Code != "" {
+		conds = append(conds, "code = "+arg(q.Code))
+	}
+
+	// Attributes not grouped by are selected as empty strings
+	columns := []string{"''", "''", "''"}
+	groupBy := []string{"1"}
+	for _, d := range q.GroupBy {
+		switch d {
+		case entity.StatsDimensionService:
+			columns[0] = "service"
+		case entity.StatsDimensionOperation:
+			columns[1] = "operation"
+		case entity.StatsDimensionCode:
+			columns[2] = "code"
+		default:
+			return nil, fmt.Errorf("pgStore.GetErrorStats: invalid dimension: %q", d)
+		}
+	}
+	for i, c := range columns {
+		if c != "''" {
+			groupBy = append(groupBy, fmt.Sprint(i+2))
+		}
+	}
+
+	rows, err := r.pool.Query(ctx, `
+		SELECT `+bucket+`, `+strings.Join(columns, ", ")+`, sum(count)::bigint
+		FROM error_counts
+		WHERE `+strings.Join(conds, " AND ")+`
+		GROUP BY `+strings.Join(groupBy, ", ")+`
+		ORDER BY 1, 2, 3, 4;
+	`, args...)
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.GetErrorStats: %w", err)
+	}
+
+	stats, err := pgx.CollectRows(rows, func(row pgx.CollectableRow) (entity.ErrorStat, error) {
+		s := entity.ErrorStat{}
+		err := row.Scan(&s.Bucket, &s.Service, &s.Operation, &s.Code, &s.Count)
+		return s, err
+	})
+	if err != nil {
+		return nil, fmt.Errorf("pgStore.GetErrorStats: %w", err)
+	}
+
+	return stats, nil
+}
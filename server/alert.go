@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/pb"
+	"github.com/code19m/sentinel/repository/store"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultListLimit = 100
+	maxListLimit     = 1000
+)
+
+func (s *server) ListAlerts(ctx context.Context, in *pb.ListAlertsRequest) (*pb.ListAlertsResponse, error) {
+	alertStatus := entity.OutboxStatus(in.GetStatus())
+	switch alertStatus {
+	case "":
+		alertStatus = entity.OutboxStatusDead
+	case entity.OutboxStatusPending, entity.OutboxStatusSent, entity.OutboxStatusSuppressed,
+		entity.OutboxStatusDead, entity.OutboxStatusDropped:
+	default:
+		return nil, status.Errorf(codes.InvalidArgument, "invalid alert status: %q", in.GetStatus())
+	}
+
+	entries, err := s.usecase.ListAlerts(ctx, alertStatus, listLimit(in.GetLimit()))
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.ListAlerts: %v", err))
+		return nil, fmt.Errorf("server.ListAlerts: %w", err)
+	}
+
+	out := &pb.ListAlertsResponse{Alerts: make([]*pb.Alert, 0, len(entries))}
+	for _, entry := range entries {
+		out.Alerts = append(out.Alerts, &pb.Alert{
+			Id:            entry.ID,
+			Kind:          string(entry.Kind),
+			Reason:        entry.Reason,
+			ErrorId:       entry.Error.ID,
+			IssueId:       entry.Error.IssueID,
+			Service:       entry.Error.Service,
+			Operation:     entry.Error.Operation,
+			Code:          entry.Error.Code,
+			Message:       entry.Error.Message,
+			Status:        string(entry.Status),
+			Attempts:      int32(entry.Attempts),
+			LastError:     entry.LastError,
+			Delivered:     entry.Delivered,
+			NextAttemptAt: timestamppb.New(entry.NextAttemptAt),
+			CreatedAt:     timestamppb.New(entry.CreatedAt),
+			UpdatedAt:     timestamppb.New(entry.UpdatedAt),
+		})
+	}
+
+	return out, nil
+}
+
+func (s *server) RetryAlert(ctx context.Context, in *pb.RetryAlertRequest) (*emptypb.Empty, error) {
+	err := s.usecase.RetryAlert(ctx, in.GetId())
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "dead or dropped alert %q not found", in.GetId())
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.RetryAlert: %v", err))
+		return nil, fmt.Errorf("server.RetryAlert: %w", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func listLimit(limit int32) int {
+	if limit <= 0 {
+		return defaultListLimit
+	}
+	return min(int(limit), maxListLimit)
+}
@@ -0,0 +1,80 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/code19m/sentinel/config"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+const apiKeyHeader = "x-api-key"
+
+var errUnauthenticated = errors.New("missing or invalid API key")
+
+// authenticate checks the API key supplied either in the "x-api-key" header or
+// as a bearer token in the "authorization" header. Authentication is disabled
+// when no API keys are configured.
+func authenticate(keys []string, authorization, apiKey string) error {
+	if len(keys) == 0 {
+		return nil
+	}
+
+	if apiKey == "" {
+		token, ok := strings.CutPrefix(authorization, "Bearer ")
+		if !ok {
+			return errUnauthenticated
+		}
+		apiKey = token
+	}
+
+	for _, key := range keys {
+		if subtle.ConstantTimeCompare([]byte(key), []byte(apiKey)) == 1 {
+			return nil
+		}
+	}
+	return errUnauthenticated
+}
+
+func authenticateGrpc(ctx context.Context, keys []string) error {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	var authorization, apiKey string
+	if values := md.Get("authorization"); len(values) > 0 {
+		authorization = values[0]
+	}
+	if values := md.Get(apiKeyHeader); len(values) > 0 {
+		apiKey = values[0]
+	}
+
+	err := authenticate(keys, authorization, apiKey)
+	if err != nil {
+		return status.Error(codes.Unauthenticated, err.Error())
+	}
+	return nil
+}
+
+func UnaryAuthInterceptor(cfg config.Config) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+		err := authenticateGrpc(ctx, cfg.APIKeys)
+		if err != nil {
+			return nil, err
+		}
+		return handler(ctx, req)
+	}
+}
+
+func StreamAuthInterceptor(cfg config.Config) grpc.StreamServerInterceptor {
+	return func(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		err := authenticateGrpc(ss.Context(), cfg.APIKeys)
+		if err != nil {
+			return err
+		}
+		return handler(srv, ss)
+	}
+}
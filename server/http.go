@@ -0,0 +1,174 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"expvar"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+
+	"github.com/code19m/sentinel/config"
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/pb"
+	"github.com/code19m/sentinel/usecase"
+	"google.golang.org/protobuf/encoding/protojson"
+)
+
+const maxHTTPBodySize = 10 << 20
+
+// errInternal is returned to clients in place of errors they cannot act on,
+// which may expose database or provider details. Those are logged instead.
+var errInternal = errors.New("internal error")
+
+// NewHTTPHandler returns the HTTP/JSON API. Request bodies use the protobuf
+// JSON mapping of pb.ErrorInfo, so both APIs accept the same fields.
+func NewHTTPHandler(
+	cfg config.Config,
+	log *slog.Logger,
+	usecase usecase.UseCase,
+) http.Handler {
+	h := &httpHandler{
+		cfg:     cfg,
+		log:     log,
+		usecase: usecase,
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /api/v1/errors", h.authenticated(h.sendErrors))
+	mux.HandleFunc("GET /debug/vars", h.authenticated(expvar.Handler().ServeHTTP))
+
+	return mux
+}
+
+type httpHandler struct {
+	cfg     config.Config
+	log     *slog.Logger
+	usecase usecase.UseCase
+}
+
+type httpErrorResult struct {
+	Accepted bool   `json:"accepted"`
+	Error    string `json:"error,omitempty"`
+}
+
+type httpBatchResult struct {
+	Results []httpErrorResult `json:"results"`
+}
+
+func (h *httpHandler) authenticated(next http.HandlerFunc) http.HandlerFunc {
+	return func(w http.ResponseWriter, r *http.Request) {
+		err := authenticate(h.cfg.APIKeys, r.Header.Get("Authorization"), r.Header.Get(apiKeyHeader))
+		if err != nil {
+			h.writeError(w, http.StatusUnauthorized, err)
+			return
+		}
+		next(w, r)
+	}
+}
+
+// sendErrors accepts either a single error object or an array of them. Both
+// are validated by the usecase, and answered with 200 once saved or, for
+// arrays, with the result of each error.
+func (h *httpHandler) sendErrors(w http.ResponseWriter, r *http.Request) {
+	body, err := io.ReadAll(http.MaxBytesReader(w, r.Body, maxHTTPBodySize))
+	var maxBytesErr *http.MaxBytesError
+	if errors.As(err, &maxBytesErr) {
+		h.writeError(w, http.StatusRequestEntityTooLarge, err)
+		return
+	}
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	body = bytes.TrimSpace(body)
+	if len(body) > 0 && body[0] == '[' {
+		h.sendBatch(w, r, body)
+		return
+	}
+
+	e, err := decodeErrorInfo(body)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	err = h.usecase.SendError(r.Context(), e)
+	if errors.Is(err, usecase.ErrInvalidError) {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+	if err != nil {
+		h.log.ErrorContext(r.Context(), fmt.Sprintf("httpHandler.sendErrors: %v", err))
+		h.writeError(w, http.StatusInternalServerError, errInternal)
+		return
+	}
+
+	h.writeJSON(w, http.StatusOK, httpErrorResult{Accepted: true})
+}
+
+func (h *httpHandler) sendBatch(w http.ResponseWriter, r *http.Request, body []byte) {
+	var items []json.RawMessage
+	err := json.Unmarshal(body, &items)
+	if err != nil {
+		h.writeError(w, http.StatusBadRequest, err)
+		return
+	}
+
+	if len(items) > h.cfg.BatchMaxSize {
+		h.writeError(w, http.StatusBadRequest,
+			fmt.Errorf("batch size %d exceeds the limit of %d", len(items), h.cfg.BatchMaxSize))
+		return
+	}
+
+	es := make([]entity.ErrorInfo, 0, len(items))
+	for i, item := range items {
+		e, err := decodeErrorInfo(item)
+		if err != nil {
+			h.writeError(w, http.StatusBadRequest, fmt.Errorf("item %d: %w", i, err))
+			return
+		}
+		es = append(es, e)
+	}
+
+	out := httpBatchResult{Results: make([]httpErrorResult, 0, len(es))}
+	for _, err := range h.usecase.SendErrors(r.Context(), es) {
+		if err != nil {
+			if !errors.Is(err, usecase.ErrInvalidError) {
+				h.log.ErrorContext(r.Context(), fmt.Sprintf("httpHandler.sendBatch: %v", err))
+				err = errInternal
+			}
+			out.Results = append(out.Results, httpErrorResult{Accepted: false, Error: err.Error()})
+			continue
+		}
+		out.Results = append(out.Results, httpErrorResult{Accepted: true})
+	}
+
+	h.writeJSON(w, http.StatusOK, out)
+}
+
+func decodeErrorInfo(data []byte) (entity.ErrorInfo, error) {
+	in := &pb.ErrorInfo{}
+	err := protojson.Unmarshal(data, in)
+	if err != nil {
+		return entity.ErrorInfo{}, fmt.Errorf("decodeErrorInfo: %w", err)
+	}
+	return toEntity(in), nil
+}
+
+func (h *httpHandler) writeError(w http.ResponseWriter, code int, err error) {
+	h.writeJSON(w, code, httpErrorResult{Accepted: false, Error: err.Error()})
+}
+
+func (h *httpHandler) writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+
+	err := json.NewEncoder(w).Encode(v)
+	if err != nil {
+		h.log.Error(fmt.Sprintf("httpHandler.writeJSON: %v", err))
+	}
+}
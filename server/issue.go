@@ -0,0 +1,96 @@
+package server
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/pb"
+	"github.com/code19m/sentinel/repository/store"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) ResolveIssue(ctx context.Context, in *pb.ResolveIssueRequest) (*pb.Issue, error) {
+	if uuid.Validate(in.GetId()) != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid issue id: %q", in.GetId())
+	}
+
+	issue, err := s.usecase.ResolveIssue(ctx, in.GetId())
+	if err != nil {
+		return nil, s.issueError(ctx, "server.ResolveIssue", in.GetId(), err)
+	}
+	return toIssue(issue), nil
+}
+
+func (s *server) ReopenIssue(ctx context.Context, in *pb.ReopenIssueRequest) (*pb.Issue, error) {
+	if uuid.Validate(in.GetId()) != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid issue id: %q", in.GetId())
+	}
+
+	issue, err := s.usecase.ReopenIssue(ctx, in.GetId())
+	if err != nil {
+		return nil, s.issueError(ctx, "server.ReopenIssue", in.GetId(), err)
+	}
+	return toIssue(issue), nil
+}
+
+func (s *server) IgnoreIssue(ctx context.Context, in *pb.IgnoreIssueRequest) (*pb.Issue, error) {
+	if uuid.Validate(in.GetId()) != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid issue id: %q", in.GetId())
+	}
+	if in.GetCount() < 0 {
+		return nil, status.Error(codes.InvalidArgument, "count must not be negative")
+	}
+
+	var until *time.Time
+	if in.GetUntil() != nil {
+		t := in.GetUntil().AsTime()
+		if !t.After(time.Now()) {
+			return nil, status.Error(codes.InvalidArgument, "until must be in the future")
+		}
+		until = &t
+	}
+
+	issue, err := s.usecase.IgnoreIssue(ctx, in.GetId(), until, in.GetCount())
+	if err != nil {
+		return nil, s.issueError(ctx, "server.IgnoreIssue", in.GetId(), err)
+	}
+	return toIssue(issue), nil
+}
+
+// issueError maps a failed issue status change to a gRPC error.
+func (s *server) issueError(ctx context.Context, op, id string, err error) error {
+	if errors.Is(err, store.ErrNotFound) {
+		return status.Errorf(codes.NotFound, "issue %q not found", id)
+	}
+	s.log.ErrorContext(ctx, fmt.Sprintf("%s: %v", op, err))
+	return fmt.Errorf("%s: %w", op, err)
+}
+
+func toIssue(issue entity.Issue) *pb.Issue {
+	out := &pb.Issue{
+		Id:               issue.ID,
+		Fingerprint:      issue.Fingerprint,
+		Code:             issue.Code,
+		Message:          issue.Message,
+		Service:          issue.Service,
+		Operation:        issue.Operation,
+		Status:           string(issue.Status),
+		FirstSeen:        timestamppb.New(issue.FirstSeen),
+		LastSeen:         timestamppb.New(issue.LastSeen),
+		Count:            issue.Count,
+		IgnoreUntilCount: issue.Ignore.UntilCount,
+	}
+	if issue.ResolvedAt != nil {
+		out.ResolvedAt = timestamppb.New(*issue.ResolvedAt)
+	}
+	if issue.Ignore.Until != nil {
+		out.IgnoreUntil = timestamppb.New(*issue.Ignore.Until)
+	}
+	return out
+}
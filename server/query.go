@@ -0,0 +1,158 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/pb"
+	"github.com/code19m/sentinel/repository/store"
+	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+func (s *server) ListErrors(ctx context.Context, in *pb.ListErrorsRequest) (*pb.ListErrorsResponse, error) {
+	filter := entity.ErrorFilter{
+		Service:   in.GetService(),
+		Operation: in.GetOperation(),
+		Code:      in.GetCode(),
+		Alerted:   in.Alerted,
+	}
+	if in.GetFrom() != nil {
+		filter.From = in.GetFrom().AsTime()
+	}
+	if in.GetTo() != nil {
+		filter.To = in.GetTo().AsTime()
+	}
+	if !filter.From.IsZero() && !filter.To.IsZero() && !filter.From.Before(filter.To) {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+
+	if in.GetCursor() != "" {
+		cursor, err := decodeCursor(in.GetCursor())
+		if err != nil {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid cursor: %v", err)
+		}
+		filter.After = &cursor
+	}
+
+	es, next, err := s.usecase.ListErrors(ctx, filter, listLimit(in.GetLimit()))
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.ListErrors: %v", err))
+		return nil, fmt.Errorf("server.ListErrors: %w", err)
+	}
+
+	out := &pb.ListErrorsResponse{Errors: make([]*pb.StoredError, 0, len(es))}
+	for _, e := range es {
+		out.Errors = append(out.Errors, toStoredError(e))
+	}
+	if next != nil {
+		out.NextCursor = encodeCursor(*next)
+	}
+
+	return out, nil
+}
+
+func (s *server) GetError(ctx context.Context, in *pb.GetErrorRequest) (*pb.StoredError, error) {
+	if uuid.Validate(in.GetId()) != nil {
+		return nil, status.Errorf(codes.InvalidArgument, "invalid error id: %q", in.GetId())
+	}
+
+	e, err := s.usecase.GetError(ctx, in.GetId())
+	if errors.Is(err, store.ErrNotFound) {
+		return nil, status.Errorf(codes.NotFound, "error %q not found", in.GetId())
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.GetError: %v", err))
+		return nil, fmt.Errorf("server.GetError: %w", err)
+	}
+
+	return toStoredError(e), nil
+}
+
+func (s *server) SearchErrors(ctx context.Context, in *pb.SearchErrorsRequest) (*pb.SearchErrorsResponse, error) {
+	q := entity.ErrorSearchQuery{
+		Query:   strings.TrimSpace(in.GetQuery()),
+		Service: in.GetService(),
+	}
+	if q.Query == "" {
+		return nil, status.Error(codes.InvalidArgument, "query is required")
+	}
+	if in.GetFrom() != nil {
+		q.From = in.GetFrom().AsTime()
+	}
+	if in.GetTo() != nil {
+		q.To = in.GetTo().AsTime()
+	}
+	if !q.From.IsZero() && !q.To.IsZero() && !q.From.Before(q.To) {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+
+	results, err := s.usecase.SearchErrors(ctx, q, listLimit(in.GetLimit()))
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.SearchErrors: %v", err))
+		return nil, fmt.Errorf("server.SearchErrors: %w", err)
+	}
+
+	out := &pb.SearchErrorsResponse{Matches: make([]*pb.ErrorMatch, 0, len(results))}
+	for _, res := range results {
+		out.Matches = append(out.Matches, &pb.ErrorMatch{
+			Error:   toStoredError(res.Error),
+			Rank:    res.Rank,
+			Snippet: res.Snippet,
+		})
+	}
+
+	return out, nil
+}
+
+func toStoredError(e entity.ErrorInfo) *pb.StoredError {
+	return &pb.StoredError{
+		Id:              e.ID,
+		Code:            e.Code,
+		Message:         e.Message,
+		Details:         e.Details,
+		Service:         e.Service,
+		Operation:       e.Operation,
+		MessageTemplate: e.MessageTemplate,
+		IssueId:         e.IssueID,
+		Fingerprint:     e.Fingerprint,
+		CreatedAt:       timestamppb.New(e.CreatedAt),
+		Alerted:         e.Alerted,
+	}
+}
+
+// encodeCursor returns an opaque page token of the form "<unix nanos>:<id>".
+func encodeCursor(c entity.ErrorCursor) string {
+	raw := strconv.FormatInt(c.CreatedAt.UnixNano(), 10) + ":" + c.ID
+	return base64.RawURLEncoding.EncodeToString([]byte(raw))
+}
+
+func decodeCursor(in string) (entity.ErrorCursor, error) {
+	raw, err := base64.RawURLEncoding.DecodeString(in)
+	if err != nil {
+		return entity.ErrorCursor{}, fmt.Errorf("decodeCursor: %w", err)
+	}
+
+	nanos, id, ok := strings.Cut(string(raw), ":")
+	if !ok {
+		return entity.ErrorCursor{}, fmt.Errorf("decodeCursor: malformed cursor")
+	}
+	n, err := strconv.ParseInt(nanos, 10, 64)
+	if err != nil {
+		return entity.ErrorCursor{}, fmt.Errorf("decodeCursor: %w", err)
+	}
+	err = uuid.Validate(id)
+	if err != nil {
+		return entity.ErrorCursor{}, fmt.Errorf("decodeCursor: %w", err)
+	}
+
+	return entity.ErrorCursor{CreatedAt: time.Unix(0, n), ID: id}, nil
+}
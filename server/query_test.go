@@ -0,0 +1,50 @@
+package server
+
+import (
+	"encoding/base64"
+	"testing"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+func TestCursorRoundTrip(t *testing.T) {
+	want := entity.ErrorCursor{
+		CreatedAt: time.Date(2024, 3, 1, 10, 20, 30, 123456789, time.UTC),
+		ID:        "8f14e45f-ceea-467f-a0e6-4f8b2c1d9e3a",
+	}
+
+	got, err := decodeCursor(encodeCursor(want))
+	if err != nil {
+		t.Fatalf("decodeCursor: %v", err)
+	}
+	if !got.CreatedAt.Equal(want.CreatedAt) || got.ID != want.ID {
+		t.Errorf("decodeCursor(encodeCursor(%v)) = %v", want, got)
+	}
+}
+
+func TestDecodeCursorMalformed(t *testing.T) {
+	encode := func(raw string) string {
+		return base64.RawURLEncoding.EncodeToString([]byte(raw))
+	}
+
+	tests := []struct {
+		name string
+		in   string
+	}{
+		{"not base64", "not a cursor!"},
+		{"no separator", encode("1709288430123456789")},
+		{"bad timestamp", encode("yesterday:8f14e45f-ceea-467f-a0e6-4f8b2c1d9e3a")},
+		{"bad id", encode("1709288430123456789:42")},
+		{"empty", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			c, err := decodeCursor(tt.in)
+			if err == nil {
+				t.Errorf("decodeCursor(%q) = %v, want an error", tt.in, c)
+			}
+		})
+	}
+}
@@ -2,6 +2,7 @@ package server
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -11,6 +12,8 @@ import (
 	"github.com/code19m/sentinel/pb"
 	"github.com/code19m/sentinel/usecase"
 	"github.com/google/uuid"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
 	"google.golang.org/protobuf/types/known/emptypb"
 )
 
@@ -34,22 +37,58 @@ type server struct {
 }
 
 func (s *server) SendError(ctx context.Context, in *pb.ErrorInfo) (*emptypb.Empty, error) {
-	e := entity.ErrorInfo{
+	err := s.usecase.SendError(ctx, toEntity(in))
+	if errors.Is(err, usecase.ErrInvalidError) {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.SendError: %v", err))
+		return nil, fmt.Errorf("server.SendError: %w", err)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (s *server) SendErrors(ctx context.Context, in *pb.ErrorBatch) (*pb.ErrorBatchResult, error) {
+	if len(in.GetErrors()) > s.cfg.BatchMaxSize {
+		return nil, status.Errorf(codes.InvalidArgument, "batch size %d exceeds the limit of %d",
+			len(in.GetErrors()), s.cfg.BatchMaxSize)
+	}
+
+	es := make([]entity.ErrorInfo, 0, len(in.GetErrors()))
+	for _, e := range in.GetErrors() {
+		es = append(es, toEntity(e))
+	}
+
+	errs := s.usecase.SendErrors(ctx, es)
+
+	out := &pb.ErrorBatchResult{Results: make([]*pb.ErrorResult, 0, len(errs))}
+	for _, err := range errs {
+		if err != nil {
+			if !errors.Is(err, usecase.ErrInvalidError) {
+				s.log.ErrorContext(ctx, fmt.Sprintf("server.SendErrors: %v", err))
+			}
+			out.Results = append(out.Results, &pb.ErrorResult{Accepted: false, Error: err.Error()})
+			continue
+		}
+		out.Results = append(out.Results, &pb.ErrorResult{Accepted: true})
+	}
+
+	return out, nil
+}
+
+func toEntity(in *pb.ErrorInfo) entity.ErrorInfo {
+	return entity.ErrorInfo{
 		ID:        uuid.New().String(),
 		Code:      in.GetCode(),
 		Message:   in.GetMessage(),
 		Details:   in.GetDetails(),
 		Service:   in.GetService(),
 		Operation: in.GetOperation(),
+
+		CustomFingerprint: in.GetFingerprint(),
+
 		CreatedAt: time.Now(),
 		Alerted:   false,
 	}
-
-	err := s.usecase.SendError(ctx, e)
-	if err != nil {
-		s.log.ErrorContext(ctx, fmt.Sprintf("server.SendError: %v", err))
-		return nil, fmt.Errorf("server.SendError: %w", err)
-	}
-
-	return &emptypb.Empty{}, nil
 }
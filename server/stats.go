@@ -0,0 +1,82 @@
+package server
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/pb"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/timestamppb"
+)
+
+const (
+	defaultStatsRange = time.Hour
+	maxStatsBuckets   = 10080 // A week of minutes
+)
+
+func (s *server) GetErrorStats(ctx context.Context, in *pb.GetErrorStatsRequest) (*pb.GetErrorStatsResponse, error) {
+	q := entity.ErrorStatsQuery{
+		Service:   in.GetService(),
+		Operation: in.GetOperation(),
+		Code:      in.GetCode(),
+		To:        time.Now(),
+		Interval:  time.Minute,
+	}
+	if in.GetTo() != nil {
+		q.To = in.GetTo().AsTime()
+	}
+	q.From = q.To.Add(-defaultStatsRange)
+	if in.GetFrom() != nil {
+		q.From = in.GetFrom().AsTime()
+	}
+	// Errors are counted per minute, so buckets start at whole minutes
+	q.From = q.From.Truncate(time.Minute)
+	if !q.From.Before(q.To) {
+		return nil, status.Error(codes.InvalidArgument, "from must be before to")
+	}
+
+	if in.GetInterval() != nil {
+		q.Interval = in.GetInterval().AsDuration()
+	}
+	if q.Interval < time.Minute || q.Interval%time.Minute != 0 {
+		return nil, status.Error(codes.InvalidArgument, "interval must be a positive multiple of a minute")
+	}
+	if q.To.Sub(q.From)/q.Interval > maxStatsBuckets {
+		return nil, status.Errorf(codes.InvalidArgument, "range has more than %d buckets, use a larger interval", maxStatsBuckets)
+	}
+
+	for _, d := range in.GetGroupBy() {
+		dimension := entity.StatsDimension(d)
+		switch dimension {
+		case entity.StatsDimensionService, entity.StatsDimensionOperation, entity.StatsDimensionCode:
+		default:
+			return nil, status.Errorf(codes.InvalidArgument, "invalid group by: %q", d)
+		}
+		if !slices.Contains(q.GroupBy, dimension) {
+			q.GroupBy = append(q.GroupBy, dimension)
+		}
+	}
+
+	stats, err := s.usecase.GetErrorStats(ctx, q)
+	if err != nil {
+		s.log.ErrorContext(ctx, fmt.Sprintf("server.GetErrorStats: %v", err))
+		return nil, fmt.Errorf("server.GetErrorStats: %w", err)
+	}
+
+	out := &pb.GetErrorStatsResponse{Stats: make([]*pb.ErrorStat, 0, len(stats))}
+	for _, stat := range stats {
+		out.Stats = append(out.Stats, &pb.ErrorStat{
+			Bucket:    timestamppb.New(stat.Bucket),
+			Service:   stat.Service,
+			Operation: stat.Operation,
+			Code:      stat.Code,
+			Count:     stat.Count,
+		})
+	}
+
+	return out, nil
+}
@@ -0,0 +1,90 @@
+package server
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/pb"
+	"github.com/code19m/sentinel/usecase"
+)
+
+func (s *server) StreamErrors(stream pb.SentinelService_StreamErrorsServer) error {
+	ctx := stream.Context()
+
+	// Received errors are handed over through a channel holding at most one batch.
+	// While a batch is being written to the store the channel fills up and the
+	// receiving goroutine stops calling Recv, so gRPC flow control pushes back
+	// on the producer instead of buffering errors in memory.
+	items := make(chan *pb.ErrorInfo, s.cfg.StreamBatchSize)
+	recvErr := make(chan error, 1)
+
+	go func() {
+		defer close(items)
+		for {
+			in, err := stream.Recv()
+			if err != nil {
+				recvErr <- err
+				return
+			}
+
+			select {
+			case items <- in:
+			case <-ctx.Done():
+				recvErr <- ctx.Err()
+				return
+			}
+		}
+	}()
+
+	summary := &pb.StreamSummary{}
+	batch := make([]entity.ErrorInfo, 0, s.cfg.StreamBatchSize)
+
+	flush := func() {
+		if len(batch) == 0 {
+			return
+		}
+
+		for _, err := range s.usecase.SendErrors(ctx, batch) {
+			if err != nil {
+				if !errors.Is(err, usecase.ErrInvalidError) {
+					s.log.ErrorContext(ctx, fmt.Sprintf("server.StreamErrors: %v", err))
+				}
+				summary.Rejected++
+				continue
+			}
+			summary.Accepted++
+		}
+
+		batch = batch[:0]
+	}
+
+	ticker := time.NewTicker(s.cfg.StreamFlushInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case in, ok := <-items:
+			if !ok {
+				flush()
+
+				err := <-recvErr
+				if errors.Is(err, io.EOF) {
+					return stream.SendAndClose(summary)
+				}
+				return fmt.Errorf("server.StreamErrors: %w", err)
+			}
+
+			summary.Received++
+			batch = append(batch, toEntity(in))
+			if len(batch) >= s.cfg.StreamBatchSize {
+				flush()
+			}
+
+		case <-ticker.C:
+			flush()
+		}
+	}
+}
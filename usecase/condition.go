@@ -0,0 +1,198 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/repository/notifier"
+	"github.com/code19m/sentinel/repository/store"
+)
+
+type ConditionType string
+
+const (
+	ConditionCount           ConditionType = "count"            // At least Threshold errors in Window
+	ConditionRate            ConditionType = "rate"             // At least Threshold errors per minute on average over Window
+	ConditionDistinctUsers   ConditionType = "distinct_users"   // Errors of at least Threshold distinct users in Window
+	ConditionPercentIncrease ConditionType = "percent_increase" // At least Threshold percent more errors in Window than in the Window before
+)
+
+// conditionPruneInterval is how often windows that ran out are deleted from the store
+const conditionPruneInterval = time.Minute
+
+// AlertCondition holds back the alerts of matching errors until it holds for
+// the errors of the same service, operation and code.
+type AlertCondition struct {
+	Name      string
+	Match     notifier.Match
+	Type      ConditionType
+	Window    time.Duration
+	Threshold float64
+
+	UserDetail string // Detail identifying the user, for ConditionDistinctUsers
+	MinCount   int64  // Errors in Window required, for ConditionPercentIncrease
+}
+
+// Conditions decides which errors are alerted.
+type Conditions interface {
+	// Match returns the conditions the error matches. The error is counted in
+	// their windows when it is saved.
+	Match(e entity.ErrorInfo) []entity.ConditionMatch
+	// Evaluate returns the reason of the first condition matched by the saved
+	// error that holds, or suppressed if none does. Errors matching no
+	// condition are alerted without a reason.
+	Evaluate(ctx context.Context, e entity.ErrorInfo) (reason string, suppressed bool, err error)
+}
+
+// NewConditions returns Conditions keeping their windows in the store, so that
+// all replicas of the service count the same errors. Windows are counted in
+// minute buckets up to the end of the minute of the error evaluated.
+func NewConditions(environment string, log *slog.Logger, store store.Store, conditions ...AlertCondition) *conditionSet {
+	var longest time.Duration
+	for _, c := range conditions {
+		longest = max(longest, c.Window)
+	}
+
+	return &conditionSet{
+		environment: environment,
+		log:         log,
+		store:       store,
+		conditions:  conditions,
+		retention:   2*longest + time.Minute,
+	}
+}
+
+type conditionSet struct {
+	environment string
+	log         *slog.Logger
+	store       store.Store
+	conditions  []AlertCondition
+	retention   time.Duration // How long windows are kept, two of the longest ones for ConditionPercentIncrease
+
+	mu        sync.Mutex
+	lastPrune time.Time
+}
+
+func (cs *conditionSet) Match(e entity.ErrorInfo) []entity.ConditionMatch {
+	var matches []entity.ConditionMatch
+	for _, c := range cs.conditions {
+		if !c.Match.Matches(cs.environment, e) {
+			continue
+		}
+
+		m := entity.ConditionMatch{Condition: c.Name}
+		if c.Type == ConditionDistinctUsers {
+			m.User = e.Details[c.UserDetail]
+		}
+		matches = append(matches, m)
+	}
+	return matches
+}
+
+func (cs *conditionSet) Evaluate(ctx context.Context, e entity.ErrorInfo) (string, bool, error) {
+	if len(cs.conditions) == 0 {
+		return "", false, nil
+	}
+
+	cs.prune(ctx)
+
+	matched := false
+	for _, c := range cs.conditions {
+		if !c.Match.Matches(cs.environment, e) {
+			continue
+		}
+		matched = true
+
+		w := entity.ConditionWindow{Condition: c.Name, Service: e.Service, Operation: e.Operation, Code: e.Code}
+		reason, err := cs.evaluate(ctx, c, w, e.CreatedAt.Truncate(time.Minute).Add(time.Minute))
+		if err != nil {
+			return "", false, fmt.Errorf("conditionSet.Evaluate: %w", err)
+		}
+		if reason != "" {
+			return reason, false, nil
+		}
+	}
+
+	return "", matched, nil
+}
+
+// prune deletes the windows that ran out, at most once per conditionPruneInterval.
+// Failing to do so only delays it.
+func (cs *conditionSet) prune(ctx context.Context) {
+	now := time.Now()
+
+	cs.mu.Lock()
+	if now.Sub(cs.lastPrune) < conditionPruneInterval {
+		cs.mu.Unlock()
+		return
+	}
+	cs.lastPrune = now
+	cs.mu.Unlock()
+
+	err := cs.store.PruneConditions(ctx, now.Add(-cs.retention))
+	if err != nil {
+		cs.log.ErrorContext(ctx, fmt.Sprintf("conditionSet.prune: %v", err))
+	}
+}
+
+// evaluate returns the reason the condition holds in the window ending at end, empty if it doesn't.
+func (cs *conditionSet) evaluate(ctx context.Context, c AlertCondition, w entity.ConditionWindow, end time.Time) (string, error) {
+	from := end.Add(-c.Window)
+
+	switch c.Type {
+	case ConditionCount:
+		count, err := cs.store.CountConditionErrors(ctx, w, from, end)
+		if err != nil {
+			return "", err
+		}
+		if float64(count) >= c.Threshold {
+			return fmt.Sprintf("%s: %d errors in %s (threshold %g)", c.Name, count, c.Window, c.Threshold), nil
+		}
+
+	case ConditionRate:
+		count, err := cs.store.CountConditionErrors(ctx, w, from, end)
+		if err != nil {
+			return "", err
+		}
+		rate := float64(count) / c.Window.Minutes()
+		if rate >= c.Threshold {
+			return fmt.Sprintf("%s: %.1f errors per minute over %s (threshold %g)", c.Name, rate, c.Window, c.Threshold), nil
+		}
+
+	case ConditionDistinctUsers:
+		users, err := cs.store.CountConditionUsers(ctx, w, from)
+		if err != nil {
+			return "", err
+		}
+		if float64(users) >= c.Threshold {
+			return fmt.Sprintf("%s: errors of %d users in %s (threshold %g)", c.Name, users, c.Window, c.Threshold), nil
+		}
+
+	case ConditionPercentIncrease:
+		current, err := cs.store.CountConditionErrors(ctx, w, from, end)
+		if err != nil {
+			return "", err
+		}
+		if current < max(c.MinCount, 1) {
+			return "", nil
+		}
+		previous, err := cs.store.CountConditionErrors(ctx, w, from.Add(-c.Window), from)
+		if err != nil {
+			return "", err
+		}
+		if previous == 0 {
+			return fmt.Sprintf("%s: %d errors in %s, none in the %s before", c.Name, current, c.Window, c.Window), nil
+		}
+		increase := float64(current-previous) / float64(previous) * 100
+		if increase >= c.Threshold {
+			return fmt.Sprintf("%s: %d errors in %s, %+.0f%% over the %s before (threshold %g%%)",
+				c.Name, current, c.Window, increase, c.Window, c.Threshold), nil
+		}
+	}
+
+	return "", nil
+}
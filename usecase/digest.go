@@ -0,0 +1,127 @@
+package usecase
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/repository/notifier"
+	"github.com/google/uuid"
+)
+
+// BuildDigest summarizes the errors from from to to in up to limit entries per section.
+func (uc usecase) BuildDigest(ctx context.Context, name string, from, to time.Time, limit int) (entity.Digest, error) {
+	d := entity.Digest{Name: name, From: from, To: to}
+
+	var err error
+	d.NewIssues, err = uc.store.ListTopIssues(ctx, entity.TopIssuesQuery{From: from, To: to, NewOnly: true}, limit)
+	if err != nil {
+		return d, fmt.Errorf("usecase.BuildDigest: %w", err)
+	}
+
+	d.TopIssues, err = uc.store.ListTopIssues(ctx, entity.TopIssuesQuery{From: from, To: to}, limit)
+	if err != nil {
+		return d, fmt.Errorf("usecase.BuildDigest: %w", err)
+	}
+
+	d.Increases, err = uc.serviceIncreases(ctx, from, to, limit)
+	if err != nil {
+		return d, fmt.Errorf("usecase.BuildDigest: %w", err)
+	}
+
+	unresolved, err := uc.store.CountUnresolvedIssues(ctx)
+	if err != nil {
+		return d, fmt.Errorf("usecase.BuildDigest: %w", err)
+	}
+	for _, c := range unresolved {
+		d.UnresolvedTotal += c.Count
+	}
+	d.Unresolved = unresolved[:min(limit, len(unresolved))]
+
+	return d, nil
+}
+
+// serviceIncreases returns up to limit services with the biggest increase of
+// errors from the period before from to the period from from to to.
+func (uc usecase) serviceIncreases(ctx context.Context, from, to time.Time, limit int) ([]entity.ServiceIncrease, error) {
+	period := to.Sub(from)
+	stats, err := uc.store.GetErrorStats(ctx, entity.ErrorStatsQuery{
+		From:     from.Add(-period),
+		To:       to,
+		Interval: period,
+		GroupBy:  []entity.StatsDimension{entity.StatsDimensionService},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("usecase.serviceIncreases: %w", err)
+	}
+
+	increases := make(map[string]*entity.ServiceIncrease)
+	for _, s := range stats {
+		inc, ok := increases[s.Service]
+		if !ok {
+			inc = &entity.ServiceIncrease{Service: s.Service}
+			increases[s.Service] = inc
+		}
+
+		if s.Bucket.Before(from) {
+			inc.Previous += s.Count
+		} else {
+			inc.Count += s.Count
+		}
+	}
+
+	out := make([]entity.ServiceIncrease, 0, len(increases))
+	for _, inc := range increases {
+		if inc.Count > inc.Previous {
+			out = append(out, *inc)
+		}
+	}
+	slices.SortFunc(out, func(a, b entity.ServiceIncrease) int {
+		return cmp.Or(
+			cmp.Compare(b.Count-b.Previous, a.Count-a.Previous),
+			cmp.Compare(a.Service, b.Service),
+		)
+	})
+
+	return out[:min(limit, len(out))], nil
+}
+
+// SendDigest builds the digest of the period and sends it to the target. The
+// first replica to take the slot of the digest and target in the store sends
+// it; the slot is held for half the interval between runs, so the other
+// replicas running the same schedule skip it and the next run takes it again.
+func (uc usecase) SendDigest(ctx context.Context, name string, target notifier.DigestTarget, from, to time.Time, interval time.Duration, limit int) error {
+	key := fmt.Sprintf("digest:%q:%q", name, target.Name)
+	owner := uuid.New().String()
+
+	acquired, err := uc.store.AcquireAlert(ctx, key, owner, interval/2)
+	if err != nil {
+		return fmt.Errorf("usecase.SendDigest: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+
+	d, err := uc.BuildDigest(ctx, name, from, to, limit)
+	if err == nil {
+		err = target.Notifier.NotifyDigest(ctx, d)
+	}
+
+	// A digest delivered to some recipients keeps the slot, so that no replica sends it again
+	var deliveryErr *notifier.DeliveryError
+	if err != nil && !(errors.As(err, &deliveryErr) && deliveryErr.Partial()) {
+		releaseErr := uc.store.ReleaseAlert(ctx, key, owner)
+		if releaseErr != nil {
+			uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.SendDigest: %v", releaseErr))
+		}
+	}
+	if err != nil {
+		return fmt.Errorf("usecase.SendDigest: %w", err)
+	}
+
+	return nil
+}
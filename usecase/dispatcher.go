@@ -0,0 +1,233 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/repository/store"
+)
+
+const (
+	// outboxClaimLimit is the maximum number of outbox entries claimed at once
+	outboxClaimLimit = 100
+	// outboxLease is how long a claimed entry is hidden from other dispatchers.
+	// It is renewed while the entry is queued or being delivered, so the entry
+	// is picked up again only if the process dies before recording the outcome.
+	outboxLease = time.Minute
+	// outboxLeaseRenewal is how often the leases of held entries are renewed
+	outboxLeaseRenewal = outboxLease / 3
+	// outboxPruneInterval is how often entries past OutboxRetention are deleted
+	outboxPruneInterval = time.Hour
+)
+
+// DispatchAlerts delivers alerts queued in the outbox until ctx is canceled.
+// The outbox is polled every PollInterval and right after new errors are
+// added. Claimed entries are delivered by Workers workers.
+func (uc usecase) DispatchAlerts(ctx context.Context) {
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	for range uc.delivery.Workers {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			uc.queue.work(ctx, uc.deliver)
+		}()
+	}
+
+	// Leases are renewed apart from the dispatch loop, which blocks while the queue is full
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		uc.renewLeases(ctx)
+	}()
+
+	wg.Add(1)
+	go func() {
+		defer wg.Done()
+		uc.pruneOutbox(ctx)
+	}()
+
+	ticker := time.NewTicker(uc.delivery.PollInterval)
+	defer ticker.Stop()
+
+	for {
+		uc.dispatchPending(ctx)
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		case <-uc.wakeup:
+		}
+	}
+}
+
+// wakeDispatcher makes the dispatcher poll the outbox without waiting for the next tick.
+func (uc usecase) wakeDispatcher() {
+	select {
+	case uc.wakeup <- struct{}{}:
+	default:
+	}
+}
+
+// renewLeases extends the leases of the entries queued or being delivered
+// every outboxLeaseRenewal until ctx is canceled.
+func (uc usecase) renewLeases(ctx context.Context) {
+	ticker := time.NewTicker(outboxLeaseRenewal)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		entries := uc.queue.heldEntries()
+		if len(entries) == 0 {
+			continue
+		}
+
+		_, err := uc.store.ExtendOutbox(ctx, entries, outboxLease)
+		if err != nil && ctx.Err() == nil {
+			uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.renewLeases: %v", err))
+		}
+	}
+}
+
+// pruneOutbox deletes the delivered entries past OutboxRetention every
+// outboxPruneInterval until ctx is canceled.
+func (uc usecase) pruneOutbox(ctx context.Context) {
+	ticker := time.NewTicker(outboxPruneInterval)
+	defer ticker.Stop()
+
+	for {
+		err := uc.store.PruneOutbox(ctx, time.Now().Add(-uc.delivery.OutboxRetention))
+		if err != nil && ctx.Err() == nil {
+			uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.pruneOutbox: %v", err))
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// dispatchPending delivers claimed outbox entries until none are due.
+func (uc usecase) dispatchPending(ctx context.Context) {
+	for ctx.Err() == nil {
+		entries, err := uc.store.ClaimOutbox(ctx, outboxClaimLimit, outboxLease)
+		if err != nil {
+			uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.dispatchPending: %v", err))
+			return
+		}
+
+		for _, entry := range entries {
+			uc.enqueue(ctx, entry)
+		}
+
+		if len(entries) < outboxClaimLimit {
+			return
+		}
+	}
+}
+
+// enqueue hands a claimed entry over to the workers. Entries given up on
+// because the queue is full are recorded in the outbox right away.
+func (uc usecase) enqueue(ctx context.Context, entry entity.OutboxEntry) {
+	switch uc.queue.push(ctx, entry) {
+	case pushDropped:
+		entry.Status = entity.OutboxStatusDropped
+		entry.LastError = "alert queue is full"
+	case pushCoalesced:
+		entry.Status = entity.OutboxStatusSuppressed
+		entry.LastError = "coalesced with a queued alert of the same issue"
+	default:
+		return
+	}
+
+	err := uc.store.UpdateOutbox(ctx, entry)
+	if err != nil {
+		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.enqueue: %v", err))
+	}
+}
+
+// deliver sends the alert of a claimed outbox entry and records the outcome.
+// Entries claimed again by another dispatcher meanwhile, e.g. after the lease
+// could not be renewed, are left to it.
+func (uc usecase) deliver(ctx context.Context, entry entity.OutboxEntry) {
+	extended, err := uc.store.ExtendOutbox(ctx, []entity.OutboxEntry{entry}, outboxLease)
+	if err != nil {
+		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.deliver: %v", err))
+		return
+	}
+	if len(extended) == 0 {
+		uc.log.WarnContext(ctx, fmt.Sprintf("usecase.deliver: outbox entry %s was claimed again, skipping", entry.ID))
+		return
+	}
+
+	sent, err := uc.handleAlert(ctx, &entry)
+
+	switch {
+	case sent:
+		entry.Status = entity.OutboxStatusSent
+		entry.LastError = ""
+	case err == nil:
+		entry.Status = entity.OutboxStatusSuppressed
+		entry.LastError = ""
+	case entry.Attempts >= uc.delivery.MaxAttempts:
+		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.deliver: giving up after %d attempts: %v", entry.Attempts, err))
+		entry.Status = entity.OutboxStatusDead
+		entry.LastError = err.Error()
+	default:
+		uc.log.WarnContext(ctx, fmt.Sprintf("usecase.deliver: attempt %d failed: %v", entry.Attempts, err))
+		entry.Status = entity.OutboxStatusPending
+		entry.LastError = err.Error()
+		entry.NextAttemptAt = time.Now().Add(uc.retryDelay(entry.Attempts))
+	}
+
+	err = uc.store.UpdateOutbox(ctx, entry)
+	if errors.Is(err, store.ErrStale) {
+		uc.log.WarnContext(ctx, fmt.Sprintf("usecase.deliver: outbox entry %s was claimed again, outcome not recorded", entry.ID))
+	} else if err != nil {
+		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.deliver: %v", err))
+	}
+}
+
+// retryDelay returns the exponential backoff delay after the given number of attempts.
+func (uc usecase) retryDelay(attempts int) time.Duration {
+	delay := uc.delivery.RetryBaseDelay
+	for i := 1; i < attempts; i++ {
+		delay *= 2
+		if delay >= uc.delivery.RetryMaxDelay {
+			return uc.delivery.RetryMaxDelay
+		}
+	}
+	return min(delay, uc.delivery.RetryMaxDelay)
+}
+
+func (uc usecase) ListAlerts(ctx context.Context, status entity.OutboxStatus, limit int) ([]entity.OutboxEntry, error) {
+	entries, err := uc.store.ListOutbox(ctx, status, limit)
+	if err != nil {
+		return nil, fmt.Errorf("usecase.ListAlerts: %w", err)
+	}
+	return entries, nil
+}
+
+func (uc usecase) RetryAlert(ctx context.Context, id string) error {
+	err := uc.store.RetryOutbox(ctx, id)
+	if err != nil {
+		return fmt.Errorf("usecase.RetryAlert: %w", err)
+	}
+
+	uc.wakeDispatcher()
+
+	return nil
+}
@@ -0,0 +1,47 @@
+package usecase
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+// fingerprint computes the key errors are grouped into issues by.
+// By default errors of the same service, operation, code and message
+// template share a fingerprint. A client supplied fingerprint replaces the
+// default one, with every "{{ default }}" element expanded to the default
+// grouping parts.
+func fingerprint(e entity.ErrorInfo) string {
+	defaultParts := []string{e.Service, e.Operation, e.Code, e.MessageTemplate}
+
+	parts := defaultParts
+	if len(e.CustomFingerprint) > 0 {
+		parts = make([]string, 0, len(e.CustomFingerprint))
+		for _, part := range e.CustomFingerprint {
+			if isDefaultPlaceholder(part) {
+				parts = append(parts, defaultParts...)
+				continue
+			}
+			parts = append(parts, part)
+		}
+	}
+
+	h := sha256.New()
+	for _, part := range parts {
+		h.Write([]byte(part))
+		h.Write([]byte{0})
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// isDefaultPlaceholder reports whether the fingerprint element is "{{ default }}",
+// ignoring whitespace inside the braces.
+func isDefaultPlaceholder(part string) bool {
+	part = strings.TrimSpace(part)
+	if !strings.HasPrefix(part, "{{") || !strings.HasSuffix(part, "}}") {
+		return false
+	}
+	return strings.TrimSpace(part[2:len(part)-2]) == "default"
+}
@@ -2,10 +2,57 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/code19m/sentinel/entity"
+	"github.com/code19m/sentinel/repository/notifier"
 )
 
 type UseCase interface {
+	// SendError saves the error. Errors without a service, operation or
+	// message are rejected with ErrInvalidError, on every entry point.
 	SendError(ctx context.Context, e entity.ErrorInfo) error
+	// SendErrors saves the errors and returns one result per error,
+	// nil if the error was accepted, validated like SendError.
+	SendErrors(ctx context.Context, es []entity.ErrorInfo) []error
+
+	// ListErrors returns up to limit stored errors matching the filter, newest
+	// first, and the cursor of the next page, nil on the last page.
+	ListErrors(ctx context.Context, filter entity.ErrorFilter, limit int) ([]entity.ErrorInfo, *entity.ErrorCursor, error)
+	GetError(ctx context.Context, id string) (entity.ErrorInfo, error)
+	// SearchErrors returns up to limit stored errors matching the full-text
+	// search, the most relevant first.
+	SearchErrors(ctx context.Context, q entity.ErrorSearchQuery, limit int) ([]entity.ErrorSearchResult, error)
+	GetErrorStats(ctx context.Context, q entity.ErrorStatsQuery) ([]entity.ErrorStat, error)
+
+	// ResolveIssue marks the issue fixed. The next error of the issue reopens
+	// it and is alerted as a regression, bypassing the alert cooldown.
+	ResolveIssue(ctx context.Context, id string) (entity.Issue, error)
+	ReopenIssue(ctx context.Context, id string) (entity.Issue, error)
+	// IgnoreIssue stops alerting errors of the issue until the given time or
+	// count more errors, whichever comes first. Nil and zero mean no limit.
+	IgnoreIssue(ctx context.Context, id string, until *time.Time, count int64) (entity.Issue, error)
+
+	ListAlerts(ctx context.Context, status entity.OutboxStatus, limit int) ([]entity.OutboxEntry, error)
+	// RetryAlert queues a dead or dropped alert for delivery again.
+	RetryAlert(ctx context.Context, id string) error
+}
+
+// Dispatcher delivers the alerts queued in the outbox.
+type Dispatcher interface {
+	DispatchAlerts(ctx context.Context)
+}
+
+// SpikeDetector alerts spikes of the error volume.
+type SpikeDetector interface {
+	DetectSpikes(ctx context.Context)
+}
+
+// DigestSender sends periodic digest reports.
+type DigestSender interface {
+	// SendDigest sends the digest of the errors from from to to, with up to
+	// limit entries per section, to the target once, even if called by
+	// several replicas. interval is the time since the previous run of the
+	// schedule.
+	SendDigest(ctx context.Context, name string, target notifier.DigestTarget, from, to time.Time, interval time.Duration, limit int) error
 }
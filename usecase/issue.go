@@ -0,0 +1,33 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+func (uc usecase) ResolveIssue(ctx context.Context, id string) (entity.Issue, error) {
+	issue, err := uc.store.SetIssueStatus(ctx, id, entity.IssueStatusResolved, nil, 0)
+	if err != nil {
+		return issue, fmt.Errorf("usecase.ResolveIssue: %w", err)
+	}
+	return issue, nil
+}
+
+func (uc usecase) ReopenIssue(ctx context.Context, id string) (entity.Issue, error) {
+	issue, err := uc.store.SetIssueStatus(ctx, id, entity.IssueStatusUnresolved, nil, 0)
+	if err != nil {
+		return issue, fmt.Errorf("usecase.ReopenIssue: %w", err)
+	}
+	return issue, nil
+}
+
+func (uc usecase) IgnoreIssue(ctx context.Context, id string, until *time.Time, count int64) (entity.Issue, error) {
+	issue, err := uc.store.SetIssueStatus(ctx, id, entity.IssueStatusIgnored, until, count)
+	if err != nil {
+		return issue, fmt.Errorf("usecase.IgnoreIssue: %w", err)
+	}
+	return issue, nil
+}
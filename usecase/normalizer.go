@@ -0,0 +1,78 @@
+package usecase
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Normalizer rewrites the variable parts of an error message (IDs, addresses,
+// timestamps, ...) to placeholders, so that messages differing only by such
+// tokens produce the same template.
+type Normalizer interface {
+	Normalize(msg string) string
+}
+
+// NormalizeRule replaces every match of Pattern with Placeholder.
+type NormalizeRule struct {
+	Pattern     *regexp.Regexp
+	Placeholder string
+}
+
+// DefaultNormalizeRules returns the built-in rules for SQL string literals, UUIDs,
+// timestamps, IP addresses, hex values and numbers. Their order matters: more
+// specific tokens are replaced before the generic number rule. String literals
+// must open after a non-word character, so that apostrophes in prose are kept.
+// Numbers end at a word boundary and take a unit suffix along (12.5ms).
+func DefaultNormalizeRules() []NormalizeRule {
+	return []NormalizeRule{
+		{regexp.MustCompile(`\B'(?:[^']|'')*'`), "<str>"},
+		{regexp.MustCompile(`\b[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}\b`), "<uuid>"},
+		{regexp.MustCompile(`\b\d{4}-\d{2}-\d{2}(?:[T ]\d{2}:\d{2}:\d{2}(?:\.\d+)?(?:Z|[+-]\d{2}:?\d{2})?)?`), "<timestamp>"},
+		{regexp.MustCompile(`\b\d{2}:\d{2}:\d{2}(?:\.\d+)?\b`), "<timestamp>"},
+		{regexp.MustCompile(`\b(?:\d{1,3}\.){3}\d{1,3}(?::\d{1,5})?\b`), "<ip>"},
+		{regexp.MustCompile(`\b(?:[0-9a-fA-F]{1,4}:){7}[0-9a-fA-F]{1,4}\b`), "<ip>"},
+		{regexp.MustCompile(`\b0x[0-9a-fA-F]+\b`), "<hex>"},
+		{regexp.MustCompile(`\b[0-9a-fA-F]{16,}\b`), "<hex>"},
+		{regexp.MustCompile(`\b\d+(?:\.\d+)?(?:[a-zA-Z]+)?\b`), "<num>"},
+	}
+}
+
+// ParseNormalizeRules parses rules in the "<placeholder>=<regexp>" form.
+func ParseNormalizeRules(in []string) ([]NormalizeRule, error) {
+	rules := make([]NormalizeRule, 0, len(in))
+	for _, s := range in {
+		placeholder, pattern, ok := strings.Cut(s, "=")
+		if !ok || placeholder == "" || pattern == "" {
+			return nil, fmt.Errorf("ParseNormalizeRules: invalid rule %q, expected <placeholder>=<regexp>", s)
+		}
+
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("ParseNormalizeRules: invalid rule %q: %w", s, err)
+		}
+
+		rules = append(rules, NormalizeRule{Pattern: re, Placeholder: placeholder})
+	}
+	return rules, nil
+}
+
+// NewNormalizer returns a Normalizer applying the given rules before the built-in ones.
+func NewNormalizer(rules ...NormalizeRule) Normalizer {
+	return regexpNormalizer{
+		rules: append(append([]NormalizeRule{}, rules...), DefaultNormalizeRules()...),
+	}
+}
+
+type regexpNormalizer struct {
+	rules []NormalizeRule
+}
+
+func (n regexpNormalizer) Normalize(msg string) string {
+	for _, rule := range n.rules {
+		msg = rule.Pattern.ReplaceAllLiteralString(msg, rule.Placeholder)
+	}
+
+	// Collapse whitespace so that formatting differences don't split an issue
+	return strings.Join(strings.Fields(msg), " ")
+}
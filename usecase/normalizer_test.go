@@ -0,0 +1,106 @@
+package usecase
+
+import (
+	"regexp"
+	"testing"
+)
+
+func TestNormalizerNormalize(t *testing.T) {
+	tests := []struct {
+		name  string
+		rules []NormalizeRule
+		msg   string
+		want  string
+	}{
+		{
+			name: "uuid",
+			msg:  "user 8f14e45f-ceea-467f-a0e6-4f8b2c1d9e3a not found",
+			want: "user <uuid> not found",
+		},
+		{
+			name: "uuid starting with digits",
+			msg:  "order 12345678-1234-1234-1234-123456789abc failed",
+			want: "order <uuid> failed",
+		},
+		{
+			name: "hex",
+			msg:  "bad pointer 0xdeadBEEF in block 0123456789abcdef0123",
+			want: "bad pointer <hex> in block <hex>",
+		},
+		{
+			name: "long hex starting with digits",
+			msg:  "commit 1234567890abcdef missing",
+			want: "commit <hex> missing",
+		},
+		{
+			name: "numbers",
+			msg:  "retry 3 of 10 took 12.5ms, limit 100",
+			want: "retry <num> of <num> took <num>, limit <num>",
+		},
+		{
+			name: "numbers inside words are kept",
+			msg:  "http2 stream reset on s3",
+			want: "http2 stream reset on s3",
+		},
+		{
+			name: "timestamps and addresses",
+			msg:  "at 2024-03-01T10:20:30.123Z from 10.0.0.12:5432",
+			want: "at <timestamp> from <ip>",
+		},
+		{
+			name: "quoted strings",
+			msg:  "duplicate key 'alice@example.com' in 'users'",
+			want: "duplicate key <str> in <str>",
+		},
+		{
+			name: "escaped quotes",
+			msg:  "value 'it''s' rejected",
+			want: "value <str> rejected",
+		},
+		{
+			name: "apostrophes in prose are kept",
+			msg:  "can't connect, server's down",
+			want: "can't connect, server's down",
+		},
+		{
+			name: "whitespace collapsed",
+			msg:  "  timeout \n\t after  5s ",
+			want: "timeout after <num>",
+		},
+		{
+			name: "custom rules applied before the built-in ones",
+			rules: []NormalizeRule{
+				{Pattern: regexp.MustCompile(`order-\d+`), Placeholder: "<order>"},
+			},
+			msg:  "order-42 failed after 3 attempts",
+			want: "<order> failed after <num> attempts",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := NewNormalizer(tt.rules...).Normalize(tt.msg)
+			if got != tt.want {
+				t.Errorf("Normalize(%q) = %q, want %q", tt.msg, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseNormalizeRules(t *testing.T) {
+	rules, err := ParseNormalizeRules([]string{`<tenant>=tenant-[a-z]+`})
+	if err != nil {
+		t.Fatalf("ParseNormalizeRules: %v", err)
+	}
+	got := NewNormalizer(rules...).Normalize("tenant-acme over quota")
+	if got != "<tenant> over quota" {
+		t.Errorf("Normalize = %q, want %q", got, "<tenant> over quota")
+	}
+
+	for _, in := range []string{"no-separator", "=pattern", "<x>=", "<x>=("} {
+		_, err := ParseNormalizeRules([]string{in})
+		if err == nil {
+			t.Errorf("ParseNormalizeRules(%q) accepted an invalid rule", in)
+		}
+	}
+}
@@ -0,0 +1,48 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+func (uc usecase) ListErrors(ctx context.Context, filter entity.ErrorFilter, limit int) ([]entity.ErrorInfo, *entity.ErrorCursor, error) {
+	// One more error tells whether there is a next page
+	es, err := uc.store.ListErrors(ctx, filter, limit+1)
+	if err != nil {
+		return nil, nil, fmt.Errorf("usecase.ListErrors: %w", err)
+	}
+
+	if len(es) <= limit {
+		return es, nil, nil
+	}
+
+	es = es[:limit]
+	last := es[len(es)-1]
+	return es, &entity.ErrorCursor{CreatedAt: last.CreatedAt, ID: last.ID}, nil
+}
+
+func (uc usecase) GetError(ctx context.Context, id string) (entity.ErrorInfo, error) {
+	e, err := uc.store.GetError(ctx, id)
+	if err != nil {
+		return e, fmt.Errorf("usecase.GetError: %w", err)
+	}
+	return e, nil
+}
+
+func (uc usecase) SearchErrors(ctx context.Context, q entity.ErrorSearchQuery, limit int) ([]entity.ErrorSearchResult, error) {
+	results, err := uc.store.SearchErrors(ctx, q, limit)
+	if err != nil {
+		return nil, fmt.Errorf("usecase.SearchErrors: %w", err)
+	}
+	return results, nil
+}
+
+func (uc usecase) GetErrorStats(ctx context.Context, q entity.ErrorStatsQuery) ([]entity.ErrorStat, error) {
+	stats, err := uc.store.GetErrorStats(ctx, q)
+	if err != nil {
+		return nil, fmt.Errorf("usecase.GetErrorStats: %w", err)
+	}
+	return stats, nil
+}
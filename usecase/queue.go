@@ -0,0 +1,158 @@
+package usecase
+
+import (
+	"context"
+	"expvar"
+	"sync"
+
+	"github.com/code19m/sentinel/config"
+	"github.com/code19m/sentinel/entity"
+)
+
+// alertQueueMetrics is published at /debug/vars of the HTTP server.
+var alertQueueMetrics = expvar.NewMap("alert_queue")
+
+type pushResult int
+
+const (
+	pushQueued pushResult = iota
+	pushDropped
+	pushCoalesced
+	pushCanceled
+)
+
+// alertQueue is a bounded queue of outbox entries consumed by a fixed number of workers.
+type alertQueue struct {
+	entries chan entity.OutboxEntry
+	policy  string
+
+	mu     sync.Mutex
+	queued map[string]int                // Number of queued entries per issue, used for coalescing
+	held   map[string]entity.OutboxEntry // Entries queued or being delivered by ID, their leases are renewed
+
+	dropped   *expvar.Int
+	coalesced *expvar.Int
+	processed *expvar.Int
+}
+
+func newAlertQueue(size int, policy string) *alertQueue {
+	q := &alertQueue{
+		entries:   make(chan entity.OutboxEntry, size),
+		policy:    policy,
+		queued:    make(map[string]int),
+		held:      make(map[string]entity.OutboxEntry),
+		dropped:   new(expvar.Int),
+		coalesced: new(expvar.Int),
+		processed: new(expvar.Int),
+	}
+
+	capacity := new(expvar.Int)
+	capacity.Set(int64(size))
+
+	alertQueueMetrics.Set("size", expvar.Func(func() any { return len(q.entries) }))
+	alertQueueMetrics.Set("capacity", capacity)
+	alertQueueMetrics.Set("dropped", q.dropped)
+	alertQueueMetrics.Set("coalesced", q.coalesced)
+	alertQueueMetrics.Set("processed", q.processed)
+
+	return q
+}
+
+// push queues the entry. When the queue is full the behaviour depends on the policy:
+//   - block waits for a free slot, so the dispatcher stops claiming new entries;
+//   - drop gives up on the entry right away;
+//   - coalesce gives up on the entry if another entry of the same issue is
+//     already queued and waits for a free slot otherwise.
+func (q *alertQueue) push(ctx context.Context, entry entity.OutboxEntry) pushResult {
+	q.track(entry, 1)
+
+	select {
+	case q.entries <- entry:
+		return pushQueued
+	default:
+	}
+
+	switch {
+	case q.policy == config.AlertQueueFullPolicyDrop:
+		q.track(entry, -1)
+		q.release(entry.ID)
+		q.dropped.Add(1)
+		return pushDropped
+
+	case q.policy == config.AlertQueueFullPolicyCoalesce && q.isQueued(entry.Error.IssueID):
+		q.track(entry, -1)
+		q.release(entry.ID)
+		q.coalesced.Add(1)
+		return pushCoalesced
+	}
+
+	select {
+	case q.entries <- entry:
+		return pushQueued
+	case <-ctx.Done():
+		q.track(entry, -1)
+		q.release(entry.ID)
+		return pushCanceled
+	}
+}
+
+// work delivers queued entries until ctx is canceled.
+func (q *alertQueue) work(ctx context.Context, deliver func(context.Context, entity.OutboxEntry)) {
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case entry := <-q.entries:
+			q.track(entry, -1)
+			deliver(ctx, entry)
+			q.release(entry.ID)
+			q.processed.Add(1)
+		}
+	}
+}
+
+// track counts the entry in or out of the queued entries of its issue. Entries
+// are held from the moment they are pushed until they are released.
+func (q *alertQueue) track(entry entity.OutboxEntry, delta int) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	if delta > 0 {
+		q.held[entry.ID] = entry
+	}
+
+	issueID := entry.Error.IssueID
+	q.queued[issueID] += delta
+	if q.queued[issueID] <= 0 {
+		delete(q.queued, issueID)
+	}
+}
+
+// release stops holding the entry once it is delivered or given up on.
+func (q *alertQueue) release(id string) {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	delete(q.held, id)
+}
+
+// heldEntries returns the entries queued or being delivered.
+func (q *alertQueue) heldEntries() []entity.OutboxEntry {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	entries := make([]entity.OutboxEntry, 0, len(q.held))
+	for _, entry := range q.held {
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+// isQueued reports whether another entry of the issue is queued.
+func (q *alertQueue) isQueued(issueID string) bool {
+	q.mu.Lock()
+	defer q.mu.Unlock()
+
+	// The entry being pushed is tracked too
+	return q.queued[issueID] > 1
+}
@@ -0,0 +1,235 @@
+package usecase
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"slices"
+	"time"
+
+	"github.com/code19m/sentinel/entity"
+	"github.com/google/uuid"
+)
+
+const (
+	// spikeInterval is how often spikes are looked for, the resolution of the error count rollup
+	spikeInterval = time.Minute
+	// spikeSeasonSpan is how far from the same time of day the baseline windows reach
+	spikeSeasonSpan = time.Hour
+	// madScale makes the median absolute deviation comparable to a standard deviation
+	madScale = 1.4826
+)
+
+// SpikeDetection configures the detection of error volume spikes, see config.SpikeConfig.
+type SpikeDetection struct {
+	Enabled    bool
+	Window     time.Duration
+	Deviations float64
+	MinCount   int64
+	Days       int
+	Cooldown   time.Duration
+}
+
+type spikeGroup struct {
+	service   string
+	operation string
+}
+
+// DetectSpikes looks for error volume spikes every minute until ctx is
+// canceled. It returns right away if spike detection is disabled.
+func (uc usecase) DetectSpikes(ctx context.Context) {
+	if !uc.spikes.Enabled {
+		return
+	}
+
+	ticker := time.NewTicker(spikeInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		err := uc.detectSpikes(ctx, time.Now())
+		if err != nil && ctx.Err() == nil {
+			uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.DetectSpikes: %v", err))
+		}
+	}
+}
+
+// detectSpikes compares the errors of each operation in the window ending at
+// the last full minute to the windows around the same time of day on the
+// previous days, and queues a spike alert for the operations deviating from them.
+func (uc usecase) detectSpikes(ctx context.Context, now time.Time) error {
+	window := uc.spikes.Window
+	end := now.Truncate(time.Minute)
+
+	current, err := uc.countWindows(ctx, end.Add(-window), 1)
+	if err != nil {
+		return fmt.Errorf("usecase.detectSpikes: %w", err)
+	}
+
+	// Operations with few errors can't spike, their baselines are not needed
+	counts := make(map[spikeGroup]int64)
+	for g, c := range current {
+		if c[0] >= uc.spikes.MinCount {
+			counts[g] = c[0]
+		}
+	}
+	if len(counts) == 0 {
+		return nil
+	}
+
+	// The windows of a day are the current one shifted by the day and those
+	// around it, so that they stay aligned to the minute buckets of the rollup
+	n := max(int(2*spikeSeasonSpan/window), 1)
+	before := time.Duration((n-1)/2) * window
+	samples := make(map[spikeGroup][]float64, len(counts))
+	for day := 1; day <= uc.spikes.Days; day++ {
+		from := end.Add(-time.Duration(day)*24*time.Hour - window - before)
+		history, err := uc.countWindows(ctx, from, n)
+		if err != nil {
+			return fmt.Errorf("usecase.detectSpikes: %w", err)
+		}
+
+		// A day without any errors around that time is taken as missing history,
+		// e.g. before Sentinel was deployed, rather than as a quiet day
+		if len(history) == 0 {
+			continue
+		}
+
+		for g := range counts {
+			c := history[g]
+			for i := range n {
+				if c == nil {
+					samples[g] = append(samples[g], 0)
+				} else {
+					samples[g] = append(samples[g], float64(c[i]))
+				}
+			}
+		}
+	}
+
+	for g, count := range counts {
+		if len(samples[g]) == 0 {
+			continue
+		}
+
+		median, deviation := baseline(samples[g])
+		score := (float64(count) - median) / deviation
+		if score < uc.spikes.Deviations {
+			continue
+		}
+
+		reason := fmt.Sprintf("%d errors in %s, usually %.0f ± %.0f at this time of day (%.1f deviations, threshold %g)",
+			count, window, median, deviation, score, uc.spikes.Deviations)
+		err = uc.alertSpike(ctx, g, end.Add(-window), reason)
+		if err != nil {
+			return fmt.Errorf("usecase.detectSpikes: %w", err)
+		}
+	}
+
+	return nil
+}
+
+// countWindows returns the errors of each operation in n consecutive windows
+// starting at from. Operations without errors in any of them are omitted.
+func (uc usecase) countWindows(ctx context.Context, from time.Time, n int) (map[spikeGroup][]int64, error) {
+	window := uc.spikes.Window
+	stats, err := uc.store.GetErrorStats(ctx, entity.ErrorStatsQuery{
+		From:     from,
+		To:       from.Add(time.Duration(n) * window),
+		Interval: window,
+		GroupBy:  []entity.StatsDimension{entity.StatsDimensionService, entity.StatsDimensionOperation},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("usecase.countWindows: %w", err)
+	}
+
+	counts := make(map[spikeGroup][]int64)
+	for _, s := range stats {
+		i := int(s.Bucket.Sub(from) / window)
+		if i < 0 || i >= n {
+			continue
+		}
+
+		g := spikeGroup{service: s.Service, operation: s.Operation}
+		if counts[g] == nil {
+			counts[g] = make([]int64, n)
+		}
+		counts[g][i] += s.Count
+	}
+
+	return counts, nil
+}
+
+// baseline returns the median of the samples and their deviation: the scaled
+// median absolute deviation, but at least the square root of the median, as
+// for Poisson distributed counts, and at least one.
+func baseline(samples []float64) (median, deviation float64) {
+	median = medianOf(samples)
+
+	deviations := make([]float64, len(samples))
+	for i, s := range samples {
+		deviations[i] = math.Abs(s - median)
+	}
+
+	deviation = max(madScale*medianOf(deviations), math.Sqrt(median), 1)
+	return median, deviation
+}
+
+func medianOf(values []float64) float64 {
+	sorted := slices.Clone(values)
+	slices.Sort(sorted)
+
+	mid := len(sorted) / 2
+	if len(sorted)%2 == 0 {
+		return (sorted[mid-1] + sorted[mid]) / 2
+	}
+	return sorted[mid]
+}
+
+// alertSpike queues a spike alert for the latest error of the operation since
+// from, unless the operation had one less than Cooldown ago. Like alerts of
+// errors, the cooldown slot is acquired in the store, so that replicas
+// detecting the same spike queue a single alert.
+func (uc usecase) alertSpike(ctx context.Context, g spikeGroup, from time.Time, reason string) error {
+	es, err := uc.store.ListErrors(ctx, entity.ErrorFilter{Service: g.service, Operation: g.operation, From: from}, 1)
+	if err != nil {
+		return fmt.Errorf("usecase.alertSpike: %w", err)
+	}
+	if len(es) == 0 {
+		return nil
+	}
+
+	entry := entity.OutboxEntry{
+		ID:     uuid.New().String(),
+		Kind:   entity.AlertKindSpike,
+		Reason: reason,
+		Error:  es[0],
+	}
+
+	key := fmt.Sprintf("spike:%q:%q", g.service, g.operation)
+	acquired, err := uc.store.AcquireAlert(ctx, key, entry.ID, uc.spikes.Cooldown)
+	if err != nil {
+		return fmt.Errorf("usecase.alertSpike: %w", err)
+	}
+	if !acquired {
+		return nil
+	}
+
+	err = uc.store.QueueAlert(ctx, entry)
+	if err != nil {
+		releaseErr := uc.store.ReleaseAlert(ctx, key, entry.ID)
+		if releaseErr != nil {
+			uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.alertSpike: %v", releaseErr))
+		}
+		return fmt.Errorf("usecase.alertSpike: %w", err)
+	}
+
+	uc.wakeDispatcher()
+
+	return nil
+}
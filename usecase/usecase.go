@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log/slog"
 	"time"
@@ -11,58 +12,204 @@ import (
 	"github.com/code19m/sentinel/repository/store"
 )
 
-func New(log *slog.Logger, store store.Store, notifier notifier.Notifier, alertCooldownMinutes int) usecase {
+// AlertDelivery configures the delivery of alerts, see the Alert* settings of config.Config.
+type AlertDelivery struct {
+	Cooldown        time.Duration
+	PollInterval    time.Duration
+	MaxAttempts     int
+	RetryBaseDelay  time.Duration
+	RetryMaxDelay   time.Duration
+	OutboxRetention time.Duration
+	Workers         int
+	QueueSize       int
+	QueueFullPolicy string
+}
+
+func New(
+	delivery AlertDelivery,
+	log *slog.Logger,
+	store store.Store,
+	notifier notifier.Notifier,
+	normalizer Normalizer,
+	conditions Conditions,
+	spikes SpikeDetection,
+) usecase {
 	return usecase{
-		log:                  log,
-		store:                store,
-		notifier:             notifier,
-		alertCooldownMinutes: alertCooldownMinutes,
+		delivery:   delivery,
+		log:        log,
+		store:      store,
+		notifier:   notifier,
+		normalizer: normalizer,
+		conditions: conditions,
+		spikes:     spikes,
+		wakeup:     make(chan struct{}, 1),
+		queue:      newAlertQueue(delivery.QueueSize, delivery.QueueFullPolicy),
 	}
 }
 
 type usecase struct {
-	log      *slog.Logger
-	store    store.Store
-	notifier notifier.Notifier
+	delivery   AlertDelivery
+	log        *slog.Logger
+	store      store.Store
+	notifier   notifier.Notifier
+	normalizer Normalizer
+	conditions Conditions
+	spikes     SpikeDetection
 
-	alertCooldownMinutes int
+	// wakeup tells the dispatcher that new alerts were queued in the outbox
+	wakeup chan struct{}
+	queue  *alertQueue
 }
 
 func (uc usecase) SendError(ctx context.Context, e entity.ErrorInfo) error {
-	err := uc.store.Add(ctx, e)
+	err := validate(e)
+	if err != nil {
+		return fmt.Errorf("usecase.SendError: %w", err)
+	}
+
+	e = uc.prepare(e)
+
+	_, err = uc.store.Add(ctx, e)
 	if err != nil {
 		return fmt.Errorf("usecase.SendError: %w", err)
 	}
 
-	go uc.handleAlert(ctx, e)
+	uc.wakeDispatcher()
 
 	return nil
 }
 
-func (uc usecase) handleAlert(ctx context.Context, e entity.ErrorInfo) {
-	ctx = context.Background()
+func (uc usecase) SendErrors(ctx context.Context, es []entity.ErrorInfo) []error {
+	results := make([]error, len(es))
 
-	lastAlerted, err := uc.store.FindLast(ctx, e.Service, e.Operation, true)
-	if err != nil && err != store.ErrNotFound {
-		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.handleAlert: %v", err))
-		return
+	// Only valid errors go to the store, remember their positions in the batch
+	valid := make([]entity.ErrorInfo, 0, len(es))
+	positions := make([]int, 0, len(es))
+	for i, e := range es {
+		err := validate(e)
+		if err != nil {
+			results[i] = fmt.Errorf("usecase.SendErrors: %w", err)
+			continue
+		}
+		valid = append(valid, uc.prepare(e))
+		positions = append(positions, i)
 	}
 
-	// Skip alerting if the last alert was sent less than AlertCooldownMinutes ago
-	if err == nil && time.Since(lastAlerted.CreatedAt) < time.Minute*time.Duration(uc.alertCooldownMinutes) {
-		return
+	if len(valid) == 0 {
+		return results
 	}
 
-	err = uc.notifier.Notify(ctx, e)
+	_, err := uc.store.AddBatch(ctx, valid)
 	if err != nil {
-		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.handleAlert: %v", err))
-		return
+		for _, i := range positions {
+			results[i] = fmt.Errorf("usecase.SendErrors: %w", err)
+		}
+		return results
+	}
+
+	uc.wakeDispatcher()
+
+	return results
+}
+
+// prepare computes the message template and fingerprint of the error and
+// the alert conditions it is counted for.
+func (uc usecase) prepare(e entity.ErrorInfo) entity.ErrorInfo {
+	e.MessageTemplate = uc.normalizer.Normalize(e.Message)
+	e.Fingerprint = fingerprint(e)
+	e.Conditions = uc.conditions.Match(e)
+	return e
+}
+
+// handleAlert notifies about the error of the outbox entry unless an error of
+// the same issue was alerted less than AlertCooldownMinutes ago. The cooldown
+// slot is acquired atomically in the store, so concurrent dispatchers, even in
+// different replicas, send at most one alert per issue per cooldown window.
+// Errors are alerted only if their alert conditions hold, see Conditions.
+// Regressions bypass conditions and the cooldown, errors of resolved or
+// ignored issues are not alerted. Spikes, deduplicated when detected, are
+// alerted regardless of the issue and don't take its cooldown slot, as they
+// concern the volume of the operation rather than the issue.
+// It reports whether the alert was sent to all of its targets. Targets it was
+// delivered to are added to the entry, so that retries only go to the others,
+// and the cooldown slot is kept for them. The kind and reason of the entry are
+// set when a condition holds.
+func (uc usecase) handleAlert(ctx context.Context, entry *entity.OutboxEntry) (bool, error) {
+	e := entry.Error
+	cooldown := uc.delivery.Cooldown
+
+	issue, err := uc.store.GetIssue(ctx, e.IssueID)
+	if err != nil {
+		return false, fmt.Errorf("usecase.handleAlert: %w", err)
+	}
+
+	// The status is read at delivery time, so issues resolved or ignored since
+	// the error was reported are not alerted either
+	if issue.Status != entity.IssueStatusUnresolved && entry.Kind != entity.AlertKindSpike {
+		return false, nil
+	}
+	if entry.Kind == entity.AlertKindRegression {
+		cooldown = 0
+	}
+
+	if entry.Kind == entity.AlertKindError {
+		reason, suppressed, err := uc.conditions.Evaluate(ctx, e)
+		if err != nil {
+			return false, fmt.Errorf("usecase.handleAlert: %w", err)
+		}
+		if suppressed {
+			return false, nil
+		}
+		if reason != "" {
+			entry.Kind = entity.AlertKindCondition
+			entry.Reason = reason
+		}
+	}
+
+	slot := entry.Kind != entity.AlertKindSpike
+	if slot {
+		acquired, err := uc.store.AcquireAlert(ctx, e.IssueID, entry.ID, cooldown)
+		if err != nil {
+			return false, fmt.Errorf("usecase.handleAlert: %w", err)
+		}
+		if !acquired {
+			return false, nil
+		}
+	}
+
+	notifyErr := uc.notifier.Notify(ctx, entity.Alert{
+		Kind:      entry.Kind,
+		Reason:    entry.Reason,
+		Error:     e,
+		Issue:     issue,
+		Delivered: entry.Delivered,
+	})
+
+	var deliveryErr *notifier.DeliveryError
+	if errors.As(notifyErr, &deliveryErr) {
+		entry.Delivered = append(entry.Delivered, deliveryErr.Delivered...)
 	}
 
+	if notifyErr != nil && len(entry.Delivered) == 0 {
+		// Give the slot back so that other errors of the issue may be alerted
+		if slot {
+			releaseErr := uc.store.ReleaseAlert(ctx, e.IssueID, entry.ID)
+			if releaseErr != nil {
+				uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.handleAlert: %v", releaseErr))
+			}
+		}
+		return false, fmt.Errorf("usecase.handleAlert: %w", notifyErr)
+	}
+
+	// The alert is already delivered, so failing to mark the error must not cause a retry
 	e.Alerted = true
 	err = uc.store.Update(ctx, e)
 	if err != nil {
 		uc.log.ErrorContext(ctx, fmt.Sprintf("usecase.handleAlert: %v", err))
-		return
 	}
+
+	if notifyErr != nil {
+		return false, fmt.Errorf("usecase.handleAlert: %w", notifyErr)
+	}
+	return true, nil
 }
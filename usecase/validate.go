@@ -0,0 +1,23 @@
+package usecase
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/code19m/sentinel/entity"
+)
+
+var ErrInvalidError = errors.New("invalid error info")
+
+func validate(e entity.ErrorInfo) error {
+	if e.Service == "" {
+		return fmt.Errorf("%w: service is required", ErrInvalidError)
+	}
+	if e.Operation == "" {
+		return fmt.Errorf("%w: operation is required", ErrInvalidError)
+	}
+	if e.Message == "" {
+		return fmt.Errorf("%w: message is required", ErrInvalidError)
+	}
+	return nil
+}